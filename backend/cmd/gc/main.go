@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/db"
+	"feedsystem_video_go/internal/media"
+	"feedsystem_video_go/internal/storage"
+	"feedsystem_video_go/internal/video"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// 手动清理孤儿上传文件：go run ./cmd/gc -dry-run 只输出报告
+func main() {
+	configPath := flag.String("config", "configs/config.yaml", "config file")
+	dryRun := flag.Bool("dry-run", false, "report orphaned files without deleting them")
+	grace := flag.Duration("grace", 0, "minimum age of orphaned files, defaults to media.orphan_grace_hours")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	sqlDB, err := db.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+	defer db.CloseDB(sqlDB)
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+
+	if *grace <= 0 {
+		*grace = time.Duration(cfg.Media.OrphanGraceHours) * time.Hour
+	}
+	urlSigner := media.NewURLSigner(cfg.Media.PublicBaseURL, cfg.Media.SigningSecret, time.Duration(cfg.Media.URLTTLSeconds)*time.Second)
+	mediaService := video.NewMediaService(video.NewMediaRepository(sqlDB), store, urlSigner)
+	sweeper := video.NewMediaSweeper(mediaService, video.NewVideoRepository(sqlDB), *grace)
+
+	report, err := sweeper.Sweep(context.Background(), *dryRun)
+	if report != nil {
+		for _, o := range report.Orphans {
+			fmt.Printf("%s\t%d\t%s\tregistered=%v\n", o.Key, o.Size, o.UploadedAt.Format(time.RFC3339), o.Registered)
+		}
+		var total int64
+		for _, o := range report.Orphans {
+			total += o.Size
+		}
+		if report.DryRun {
+			fmt.Printf("dry run: %d orphaned files (%d bytes) uploaded before %s\n", len(report.Orphans), total, report.Before.Format(time.RFC3339))
+		} else {
+			fmt.Printf("deleted %d of %d orphaned files, freed %d bytes, %d failed\n", report.Deleted, len(report.Orphans), report.FreedBytes, report.Failed)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Sweep failed: %v", err)
+	}
+}
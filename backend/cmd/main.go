@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"feedsystem_video_go/internal/account"
 	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/db"
 	apphttp "feedsystem_video_go/internal/http"
+	"feedsystem_video_go/internal/media"
 	rabbitmq "feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/storage"
+	"feedsystem_video_go/internal/video"
 	"log"
 	"strconv"
 	"time"
@@ -49,6 +53,14 @@ func main() {
 		}
 	}
 
+	// 提升配置中的管理员账号
+	if len(cfg.Admin.AccountIDs) > 0 {
+		accountService := account.NewAccountService(account.NewAccountRepository(sqlDB), cache)
+		if err := accountService.PromoteAdmins(context.Background(), cfg.Admin.AccountIDs); err != nil {
+			log.Printf("Failed to promote admin accounts: %v", err)
+		}
+	}
+
 	// 连接 RabbitMQ (可选，用于消息队列)
 	rmq, err := rabbitmq.NewRabbitMQ(&cfg.RabbitMQ)
 	if err != nil {
@@ -59,8 +71,23 @@ func main() {
 		log.Printf("RabbitMQ connected")
 	}
 
+	// 初始化存储后端
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+
+	// 旧数据中的媒体地址转换为存储 key
+	urlSigner := media.NewURLSigner(cfg.Media.PublicBaseURL, cfg.Media.SigningSecret, time.Duration(cfg.Media.URLTTLSeconds)*time.Second)
+	mediaService := video.NewMediaService(video.NewMediaRepository(sqlDB), store, urlSigner)
+	if n, err := video.MigrateMediaKeys(context.Background(), video.NewVideoRepository(sqlDB), mediaService); err != nil {
+		log.Printf("Failed to migrate media keys: %v", err)
+	} else if n > 0 {
+		log.Printf("Migrated media keys for %d videos", n)
+	}
+
 	// 设置路由
-	r := apphttp.SetRouter(sqlDB, cache, rmq)
+	r := apphttp.SetRouter(&cfg, sqlDB, cache, rmq, store)
 	log.Printf("Server is running on port %d", cfg.Server.Port)
 	if err := r.Run(":" + strconv.Itoa(cfg.Server.Port)); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
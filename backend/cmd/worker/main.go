@@ -4,8 +4,10 @@ import (
 	"context"
 	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/db"
+	"feedsystem_video_go/internal/media"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
 	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/storage"
 	"feedsystem_video_go/internal/video"
 	"feedsystem_video_go/internal/worker"
 	"log"
@@ -34,6 +36,21 @@ const (
 	popularityExchange   = "video.popularity.events"
 	popularityQueue      = "video.popularity.events"
 	popularityBindingKey = "video.popularity.*"
+
+	videoExchange   = "video.events"
+	videoQueue      = "video.events"
+	videoBindingKey = "video.*"
+
+	packagingQueue      = "video.packaging"
+	packagingBindingKey = "video.uploaded"
+
+	historyExchange   = "history.events"
+	historyQueue      = "history.events"
+	historyBindingKey = "history.*"
+
+	collectExchange   = "collect.events"
+	collectQueue      = "collect.events"
+	collectBindingKey = "collect.*"
 )
 
 func main() {
@@ -90,6 +107,18 @@ func main() {
 	if err := declareCommentTopology(ch); err != nil {
 		log.Fatalf("Failed to declare comment topology: %v", err)
 	}
+	if err := declareVideoTopology(ch); err != nil {
+		log.Fatalf("Failed to declare video topology: %v", err)
+	}
+	if err := declarePackagingTopology(ch); err != nil {
+		log.Fatalf("Failed to declare packaging topology: %v", err)
+	}
+	if err := declareHistoryTopology(ch); err != nil {
+		log.Fatalf("Failed to declare history topology: %v", err)
+	}
+	if err := declareCollectTopology(ch); err != nil {
+		log.Fatalf("Failed to declare collect topology: %v", err)
+	}
 	if cache != nil {
 		if err := declarePopularityTopology(ch); err != nil {
 			log.Fatalf("Failed to declare popularity topology: %v", err)
@@ -99,6 +128,11 @@ func main() {
 		log.Fatalf("Failed to set qos: %v", err)
 	}
 
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+
 	repo := social.NewSocialRepository(sqlDB)
 	socialWorker := worker.NewSocialWorker(ch, repo, socialQueue)
 	videoRepo := video.NewVideoRepository(sqlDB)
@@ -106,25 +140,66 @@ func main() {
 	commentRepo := video.NewCommentRepository(sqlDB)
 	likeWorker := worker.NewLikeWorker(ch, likeRepo, videoRepo, likeQueue)
 	commentWorker := worker.NewCommentWorker(ch, commentRepo, videoRepo, commentQueue)
+	urlSigner := media.NewURLSigner(cfg.Media.PublicBaseURL, cfg.Media.SigningSecret, time.Duration(cfg.Media.URLTTLSeconds)*time.Second)
+	mediaService := video.NewMediaService(video.NewMediaRepository(sqlDB), store, urlSigner)
+	hlsPackager := video.NewHLSPackager(mediaService, videoRepo, time.Duration(cfg.Media.HLSSegmentSeconds)*time.Second)
+	videoCleaner := video.NewVideoCleaner(videoRepo, likeRepo, commentRepo, repo, cache, mediaService, hlsPackager, video.NewSeriesRepository(sqlDB))
+	videoWorker := worker.NewVideoWorker(ch, videoCleaner, videoQueue)
+	packagingWorker := worker.NewPackagingWorker(ch, videoCleaner, packagingQueue)
+	historyWorker := worker.NewHistoryWorker(ch, video.NewHistoryRepository(sqlDB), historyQueue)
+	collectWorker := worker.NewCollectWorker(ch, video.NewCollectionRepository(sqlDB), videoRepo, collectQueue)
+	publishScheduler := worker.NewPublishScheduler(videoRepo, videoCleaner, 10*time.Second)
+	uploadService := video.NewUploadService(video.NewUploadRepository(sqlDB), mediaService)
+	uploadSweeper := worker.NewUploadSessionSweeper(uploadService, 10*time.Minute)
+	mediaSweeper := video.NewMediaSweeper(mediaService, videoRepo, time.Duration(cfg.Media.OrphanGraceHours)*time.Hour)
+	sweepInterval := time.Duration(cfg.Media.OrphanSweepMinutes) * time.Minute
+	if sweepInterval <= 0 {
+		sweepInterval = time.Hour
+	}
+	orphanSweeper := worker.NewOrphanMediaSweeper(mediaSweeper, sweepInterval)
 	var popularityWorker *worker.PopularityWorker
+	var playStatsFlusher *worker.PlayStatsFlusher
 	if cache != nil {
 		popularityWorker = worker.NewPopularityWorker(ch, cache, popularityQueue)
+		flushInterval := time.Duration(cfg.Play.FlushSeconds) * time.Second
+		if flushInterval <= 0 {
+			flushInterval = 30 * time.Second
+		}
+		playStatsFlusher = worker.NewPlayStatsFlusher(cache, videoRepo, flushInterval)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	errCh := make(chan error, 4)
+	errCh := make(chan error, 12)
 	log.Printf("Worker started, consuming queue=%s", socialQueue)
 	go func() { errCh <- socialWorker.Run(ctx) }()
 	log.Printf("Worker started, consuming queue=%s", likeQueue)
 	go func() { errCh <- likeWorker.Run(ctx) }()
 	log.Printf("Worker started, consuming queue=%s", commentQueue)
 	go func() { errCh <- commentWorker.Run(ctx) }()
+	log.Printf("Worker started, consuming queue=%s", videoQueue)
+	go func() { errCh <- videoWorker.Run(ctx) }()
+	log.Printf("Worker started, consuming queue=%s", packagingQueue)
+	go func() { errCh <- packagingWorker.Run(ctx) }()
+	log.Printf("Worker started, consuming queue=%s", historyQueue)
+	go func() { errCh <- historyWorker.Run(ctx) }()
+	log.Printf("Worker started, consuming queue=%s", collectQueue)
+	go func() { errCh <- collectWorker.Run(ctx) }()
+	log.Printf("Publish scheduler started")
+	go func() { errCh <- publishScheduler.Run(ctx) }()
+	log.Printf("Upload session sweeper started")
+	go func() { errCh <- uploadSweeper.Run(ctx) }()
+	log.Printf("Orphan media sweeper started")
+	go func() { errCh <- orphanSweeper.Run(ctx) }()
 	if popularityWorker != nil {
 		log.Printf("Worker started, consuming queue=%s", popularityQueue)
 		go func() { errCh <- popularityWorker.Run(ctx) }()
 	}
+	if playStatsFlusher != nil {
+		log.Printf("Play stats flusher started")
+		go func() { errCh <- playStatsFlusher.Run(ctx) }()
+	}
 
 	err = <-errCh
 	if err != nil && err != context.Canceled {
@@ -271,3 +346,140 @@ func declareCommentTopology(ch *amqp.Channel) error {
 		nil,
 	)
 }
+
+func declareVideoTopology(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(
+		videoExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		videoQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(
+		q.Name,
+		videoBindingKey,
+		videoExchange,
+		false,
+		nil,
+	)
+}
+
+// 打包队列与视频事件共用 video.events 交换机，只接收 video.uploaded
+func declarePackagingTopology(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(
+		videoExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		packagingQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(
+		q.Name,
+		packagingBindingKey,
+		videoExchange,
+		false,
+		nil,
+	)
+}
+
+func declareHistoryTopology(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(
+		historyExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		historyQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(
+		q.Name,
+		historyBindingKey,
+		historyExchange,
+		false,
+		nil,
+	)
+}
+
+func declareCollectTopology(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(
+		collectExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		collectQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(
+		q.Name,
+		collectBindingKey,
+		collectExchange,
+		false,
+		nil,
+	)
+}
@@ -1,10 +1,98 @@
 package account
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderOther  = "other"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// 角色等级，用于比较权限高低
+var roleRank = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleRank 未知角色按普通用户处理
+func RoleRank(role string) int {
+	if rank, ok := roleRank[role]; ok {
+		return rank
+	}
+	return roleRank[RoleUser]
+}
+
 type Account struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Username string `gorm:"unique" json:"username"`
 	Password string `json:"-"`
 	Token    string `json:"-"`
+	Role     string `gorm:"type:varchar(16);not null;default:user" json:"role"`
+	Banned   bool   `gorm:"not null;default:false;index" json:"banned,omitempty"`
+	// 临时封禁：到期自动解除
+	SuspendedUntil *time.Time `gorm:"index" json:"suspended_until,omitempty"`
+	SuspendReason  string     `gorm:"type:varchar(255)" json:"suspend_reason,omitempty"`
+	// 点赞列表是否对他人公开，默认仅自己可见
+	LikesPublic bool `gorm:"not null;default:false" json:"likes_public"`
+	// 个人资料，头像复用封面上传流程
+	DisplayName string     `gorm:"type:varchar(64)" json:"display_name"`
+	AvatarKey   string     `gorm:"type:varchar(255)" json:"-"`
+	Bio         string     `gorm:"type:varchar(255)" json:"bio"`
+	Gender      string     `gorm:"type:varchar(16);not null;default:''" json:"gender"`
+	Birthday    *time.Time `gorm:"type:date" json:"-"`
+	// 计数器：关注/点赞/发布事件时增量维护，读资料时不再聚合
+	FollowersCount int64 `gorm:"not null;default:0" json:"followers_count"`
+	FollowingCount int64 `gorm:"not null;default:0" json:"following_count"`
+	LikesReceived  int64 `gorm:"not null;default:0" json:"likes_received"`
+	VideosCount    int64 `gorm:"not null;default:0" json:"videos_count"`
+}
+
+func (a *Account) IsSuspended(now time.Time) bool {
+	return a.SuspendedUntil != nil && now.Before(*a.SuspendedUntil)
+}
+
+// RestrictedIDs 被封禁或处于暂停期的账号 ID 子查询，用于在列表中过滤其内容
+func RestrictedIDs(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Model(&Account{}).
+		Select("id").
+		Where("banned = ? OR suspended_until > ?", true, time.Now())
+}
+
+// 计数器列名
+const (
+	CounterFollowers     = "followers_count"
+	CounterFollowing     = "following_count"
+	CounterLikesReceived = "likes_received"
+	CounterVideos        = "videos_count"
+)
+
+// ChangeCounter 增减账号计数器，减到 0 为止；db 可以是调用方的事务
+func ChangeCounter(db *gorm.DB, id uint, column string, delta int64) error {
+	if id == 0 || delta == 0 {
+		return nil
+	}
+	expr := gorm.Expr(column+" + ?", delta)
+	if delta < 0 {
+		expr = gorm.Expr("GREATEST("+column+" - ?, 0)", -delta)
+	}
+	return db.Model(&Account{}).Where("id = ?", id).UpdateColumn(column, expr).Error
 }
 
 type CreateAccountRequest struct {
@@ -16,6 +104,48 @@ type RenameRequest struct {
 	NewUsername string `json:"new_username"`
 }
 
+type SetLikesPublicRequest struct {
+	LikesPublic bool `json:"likes_public"`
+}
+
+// UpdateProfileRequest 只更新传入的字段；avatar_key 可传上传封面得到的 key 或地址，空字符串表示清除
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name"`
+	AvatarKey   *string `json:"avatar_key"`
+	Bio         *string `json:"bio"`
+	Gender      *string `json:"gender"`
+	// 格式 2006-01-02，空字符串表示清除
+	Birthday *string `json:"birthday"`
+}
+
+type GetProfileRequest struct {
+	ID uint `json:"id"`
+}
+
+// PublicAccount 列表中展示的账号信息
+type PublicAccount struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+// Profile 公开资料
+type Profile struct {
+	ID             uint   `json:"id"`
+	Username       string `json:"username"`
+	DisplayName    string `json:"display_name"`
+	AvatarURL      string `json:"avatar_url"`
+	Bio            string `json:"bio"`
+	Gender         string `json:"gender,omitempty"`
+	Birthday       string `json:"birthday,omitempty"`
+	FollowersCount int64  `json:"followers_count"`
+	FollowingCount int64  `json:"following_count"`
+	LikesReceived  int64  `json:"likes_received"`
+	VideosCount    int64  `json:"videos_count"`
+	LikesPublic    bool   `json:"likes_public"`
+}
+
 type FindByIDRequest struct {
 	ID uint `json:"id"`
 }
@@ -44,3 +174,9 @@ type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+type SuspendRequest struct {
+	AccountID uint   `json:"account_id"`
+	Until     int64  `json:"until"`
+	Reason    string `json:"reason"`
+}
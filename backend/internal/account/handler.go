@@ -61,6 +61,28 @@ func (h *AccountHandler) Rename(c *gin.Context) {
 	c.JSON(200, gin.H{"token": token})
 }
 
+func (h *AccountHandler) SetLikesPublic(c *gin.Context) {
+	var req SetLikesPublicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := getAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.accountService.SetLikesPublic(c.Request.Context(), accountID, req.LikesPublic); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "account not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"likes_public": req.LikesPublic})
+}
+
 func (h *AccountHandler) ChangePassword(c *gin.Context) {
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,6 +131,10 @@ func (h *AccountHandler) Login(c *gin.Context) {
 		return
 	}
 	if token, err := h.accountService.Login(c.Request.Context(), req.Username, req.Password); err != nil {
+		if errors.Is(err, ErrAccountBanned) || errors.Is(err, ErrAccountSuspended) {
+			c.JSON(403, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	} else {
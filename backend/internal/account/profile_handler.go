@@ -0,0 +1,67 @@
+package account
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProfileHandler struct {
+	service *ProfileService
+}
+
+func NewProfileHandler(service *ProfileService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+func (h *ProfileHandler) Get(c *gin.Context) {
+	var req GetProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	// 可选登录：本人查看时额外返回性别和生日
+	viewerAccountID, _ := getAccountID(c)
+	profile, err := h.service.Get(c.Request.Context(), req.ID, viewerAccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "account not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, profile)
+}
+
+func (h *ProfileHandler) Update(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := getAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	profile, err := h.service.Update(c.Request.Context(), accountID, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "account not found"})
+			return
+		}
+		if errors.Is(err, ErrAvatarConflict) {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, profile)
+}
@@ -0,0 +1,179 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxDisplayNameLength = 32
+	maxBioLength         = 200
+	birthdayLayout       = "2006-01-02"
+)
+
+var (
+	ErrDisplayNameTooLong = errors.New("display_name is too long")
+	ErrBioTooLong         = errors.New("bio is too long")
+	ErrInvalidGender      = errors.New("invalid gender")
+	ErrInvalidBirthday    = errors.New("birthday must be a past date in 2006-01-02 format")
+	ErrAvatarConflict     = errors.New("avatar was changed concurrently, please retry")
+)
+
+// AvatarMedia 头像复用封面的上传校验与引用计数，由 video.MediaService 实现，避免 account 依赖 video
+type AvatarMedia interface {
+	NormalizeKey(raw string) string
+	RetainCover(ctx context.Context, key string, ownerID uint) error
+	Release(ctx context.Context, key string) error
+	SignURL(key string) string
+}
+
+type ProfileService struct {
+	repo  *AccountRepository
+	media AvatarMedia
+}
+
+func NewProfileService(repo *AccountRepository, media AvatarMedia) *ProfileService {
+	return &ProfileService{repo: repo, media: media}
+}
+
+// Get 读取公开资料，性别和生日只返回给本人
+func (s *ProfileService) Get(ctx context.Context, id uint, viewerAccountID uint) (*Profile, error) {
+	account, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toProfile(account, account.ID == viewerAccountID), nil
+}
+
+func (s *ProfileService) Update(ctx context.Context, accountID uint, req UpdateProfileRequest) (*Profile, error) {
+	account, err := s.repo.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.DisplayName != nil {
+		name := strings.TrimSpace(*req.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return nil, ErrDisplayNameTooLong
+		}
+		updates["display_name"] = name
+	}
+	if req.Bio != nil {
+		bio := strings.TrimSpace(*req.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return nil, ErrBioTooLong
+		}
+		updates["bio"] = bio
+	}
+	if req.Gender != nil {
+		gender := strings.TrimSpace(*req.Gender)
+		switch gender {
+		case "", GenderMale, GenderFemale, GenderOther:
+		default:
+			return nil, ErrInvalidGender
+		}
+		updates["gender"] = gender
+	}
+	if req.Birthday != nil {
+		raw := strings.TrimSpace(*req.Birthday)
+		if raw == "" {
+			updates["birthday"] = nil
+		} else {
+			birthday, err := time.Parse(birthdayLayout, raw)
+			if err != nil || !birthday.Before(time.Now()) {
+				return nil, ErrInvalidBirthday
+			}
+			updates["birthday"] = birthday
+		}
+	}
+
+	// 新头像先增加引用，资料写入成功后再释放旧头像
+	oldAvatar := account.AvatarKey
+	newAvatar := oldAvatar
+	if req.AvatarKey != nil {
+		newAvatar = strings.TrimSpace(*req.AvatarKey)
+		if s.media != nil {
+			newAvatar = s.media.NormalizeKey(newAvatar)
+		}
+		if newAvatar != oldAvatar {
+			if newAvatar != "" && s.media != nil {
+				if err := s.media.RetainCover(ctx, newAvatar, accountID); err != nil {
+					return nil, err
+				}
+			}
+			updates["avatar_key"] = newAvatar
+		}
+	}
+
+	if newAvatar == oldAvatar {
+		if err := s.repo.UpdateProfile(ctx, accountID, updates); err != nil {
+			return nil, err
+		}
+		return s.Get(ctx, accountID, accountID)
+	}
+	// 头像以读到的旧值为条件写入，并发修改时只有一方成功，旧头像只会被释放一次
+	updated, err := s.repo.UpdateProfileIfAvatar(ctx, accountID, oldAvatar, updates)
+	if err != nil || !updated {
+		s.releaseAvatar(ctx, newAvatar)
+		if err == nil {
+			err = ErrAvatarConflict
+		}
+		return nil, err
+	}
+	s.releaseAvatar(ctx, oldAvatar)
+	return s.Get(ctx, accountID, accountID)
+}
+
+func (s *ProfileService) releaseAvatar(ctx context.Context, key string) {
+	if key == "" || s.media == nil {
+		return
+	}
+	if err := s.media.Release(ctx, key); err != nil {
+		log.Printf("profile service: failed to release avatar %s: %v", key, err)
+	}
+}
+
+// NewPublicAccount 转换为列表展示用的账号信息，media 为 nil 时不返回头像地址
+func NewPublicAccount(a *Account, media AvatarMedia) PublicAccount {
+	return PublicAccount{
+		ID:          a.ID,
+		Username:    a.Username,
+		DisplayName: a.DisplayName,
+		AvatarURL:   avatarURL(a, media),
+	}
+}
+
+func avatarURL(a *Account, media AvatarMedia) string {
+	if a.AvatarKey == "" || media == nil {
+		return ""
+	}
+	return media.SignURL(a.AvatarKey)
+}
+
+func (s *ProfileService) toProfile(a *Account, owner bool) *Profile {
+	p := &Profile{
+		ID:             a.ID,
+		Username:       a.Username,
+		DisplayName:    a.DisplayName,
+		Bio:            a.Bio,
+		AvatarURL:      avatarURL(a, s.media),
+		FollowersCount: a.FollowersCount,
+		FollowingCount: a.FollowingCount,
+		LikesReceived:  a.LikesReceived,
+		VideosCount:    a.VideosCount,
+		LikesPublic:    a.LikesPublic,
+	}
+	if !owner {
+		return p
+	}
+	p.Gender = a.Gender
+	if a.Birthday != nil {
+		p.Birthday = a.Birthday.Format(birthdayLayout)
+	}
+	return p
+}
@@ -2,6 +2,7 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -63,6 +64,35 @@ func (ar *AccountRepository) FindByID(ctx context.Context, id uint) (*Account, e
 	return &account, nil
 }
 
+func (ar *AccountRepository) FindByIDs(ctx context.Context, ids []uint) ([]Account, error) {
+	var accounts []Account
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+	if err := ar.db.WithContext(ctx).Where("id IN ?", ids).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// BannedAmong ids 中被封禁的账号
+func (ar *AccountRepository) BannedAmong(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	banned := make(map[uint]bool)
+	if len(ids) == 0 {
+		return banned, nil
+	}
+	var found []uint
+	if err := ar.db.WithContext(ctx).Model(&Account{}).
+		Where("id IN ? AND banned = ?", ids, true).
+		Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		banned[id] = true
+	}
+	return banned, nil
+}
+
 func (ar *AccountRepository) FindByUsername(ctx context.Context, username string) (*Account, error) {
 	var account Account
 	if err := ar.db.WithContext(ctx).Where("username = ?", username).First(&account).Error; err != nil {
@@ -84,3 +114,101 @@ func (ar *AccountRepository) Logout(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+func (ar *AccountRepository) SetBanned(ctx context.Context, id uint, banned bool) error {
+	updates := map[string]interface{}{"banned": banned}
+	if banned {
+		updates["token"] = ""
+	}
+	result := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := ar.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 修改角色同时清空 token，旧 token 中携带的角色立即失效
+func (ar *AccountRepository) SetRole(ctx context.Context, id uint, role string) error {
+	result := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"role": role, "token": ""})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 角色和 token 都未变化时 MySQL 也返回 0 行，需要再确认账号是否存在
+	if result.RowsAffected == 0 {
+		if _, err := ar.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 将配置中的账号提升为管理员，仅在还没有任何管理员时生效，用于初始化第一个管理员；
+// 之后的角色调整以 setRole 为准，重启不会撤销降级
+func (ar *AccountRepository) PromoteAdmins(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var admins int64
+	if err := ar.db.WithContext(ctx).Model(&Account{}).Where("role = ?", RoleAdmin).Count(&admins).Error; err != nil {
+		return 0, err
+	}
+	if admins > 0 {
+		return 0, nil
+	}
+	result := ar.db.WithContext(ctx).Model(&Account{}).
+		Where("id IN ? AND role <> ?", ids, RoleAdmin).
+		Updates(map[string]interface{}{"role": RoleAdmin, "token": ""})
+	return result.RowsAffected, result.Error
+}
+
+func (ar *AccountRepository) SetLikesPublic(ctx context.Context, id uint, public bool) error {
+	result := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Update("likes_public", public)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := ar.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ar *AccountRepository) UpdateProfile(ctx context.Context, id uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateProfileIfAvatar 仅在头像仍为 avatarKey 时写入，返回是否写入；updates 中包含新头像，写入后行一定有变化
+func (ar *AccountRepository) UpdateProfileIfAvatar(ctx context.Context, id uint, avatarKey string, updates map[string]interface{}) (bool, error) {
+	result := ar.db.WithContext(ctx).Model(&Account{}).
+		Where("id = ? AND avatar_key = ?", id, avatarKey).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// 设置/解除临时封禁；设置时清空 token
+func (ar *AccountRepository) SetSuspension(ctx context.Context, id uint, until *time.Time, reason string) error {
+	updates := map[string]interface{}{"suspended_until": until, "suspend_reason": reason}
+	if until != nil {
+		updates["token"] = ""
+	}
+	result := ar.db.WithContext(ctx).Model(&Account{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := ar.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
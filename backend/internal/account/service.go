@@ -6,6 +6,7 @@ import (
 	"feedsystem_video_go/internal/auth"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	rediscache "feedsystem_video_go/internal/middleware/redis"
@@ -23,8 +24,19 @@ type AccountService struct {
 var (
 	ErrUsernameTaken       = errors.New("username already exists")
 	ErrNewUsernameRequired = errors.New("new_username is required")
+	ErrAccountBanned       = errors.New("account has been banned")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrAccountSuspended    = errors.New("account has been suspended")
+	ErrInvalidSuspension   = errors.New("suspension must end in the future")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrCannotTargetSelf    = errors.New("can not apply to self")
 )
 
+// SuspendedCacheKey 暂停期间存在的标记 key，TTL 与暂停剩余时间一致
+func SuspendedCacheKey(accountID uint) string {
+	return fmt.Sprintf("account:suspended:%d", accountID)
+}
+
 func NewAccountService(accountRepository *AccountRepository, cache *rediscache.Client) *AccountService {
 	return &AccountService{accountRepository: accountRepository, cache: cache}
 }
@@ -46,7 +58,11 @@ func (as *AccountService) Rename(ctx context.Context, accountID uint, newUsernam
 		return "", ErrNewUsernameRequired
 	}
 
-	token, err := auth.GenerateToken(accountID, newUsername)
+	account, err := as.FindByID(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+	token, err := auth.GenerateToken(accountID, newUsername, account.Role)
 	if err != nil {
 		return "", err
 	}
@@ -117,8 +133,14 @@ func (as *AccountService) Login(ctx context.Context, username, password string)
 	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
 		return "", err
 	}
+	if account.Banned {
+		return "", ErrAccountBanned
+	}
+	if account.IsSuspended(time.Now()) {
+		return "", fmt.Errorf("%w until %s", ErrAccountSuspended, account.SuspendedUntil.Format(time.RFC3339))
+	}
 	// generate token
-	token, err := auth.GenerateToken(account.ID, account.Username)
+	token, err := auth.GenerateToken(account.ID, account.Username, account.Role)
 	if err != nil {
 		return "", err
 	}
@@ -154,3 +176,112 @@ func (as *AccountService) Logout(ctx context.Context, accountID uint) error {
 	}
 	return as.accountRepository.Logout(ctx, account.ID)
 }
+
+func (as *AccountService) SetLikesPublic(ctx context.Context, accountID uint, public bool) error {
+	return as.accountRepository.SetLikesPublic(ctx, accountID, public)
+}
+
+// CheckOperator 管理操作只能作用于角色低于操作者的其他账号
+func (as *AccountService) CheckOperator(ctx context.Context, operatorID uint, operatorRole string, accountID uint) error {
+	if operatorID == accountID {
+		return ErrCannotTargetSelf
+	}
+	target, err := as.accountRepository.FindByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if RoleRank(target.Role) >= RoleRank(operatorRole) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// 封禁/解封账号；封禁时清空 token 并删除 Redis 缓存，已签发的 token 立即失效
+func (as *AccountService) SetBanned(ctx context.Context, accountID uint, banned bool) error {
+	if err := as.accountRepository.SetBanned(ctx, accountID, banned); err != nil {
+		return err
+	}
+	if banned && as.cache != nil {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if err := as.cache.Del(cacheCtx, fmt.Sprintf("account:%d", accountID)); err != nil {
+			log.Printf("failed to del cache: %v", err)
+		}
+	}
+	return nil
+}
+
+func (as *AccountService) SetRole(ctx context.Context, accountID uint, role string) error {
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if err := as.accountRepository.SetRole(ctx, accountID, role); err != nil {
+		return err
+	}
+	if as.cache != nil {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if err := as.cache.Del(cacheCtx, fmt.Sprintf("account:%d", accountID)); err != nil {
+			log.Printf("failed to del cache: %v", err)
+		}
+	}
+	return nil
+}
+
+func (as *AccountService) PromoteAdmins(ctx context.Context, ids []uint) error {
+	n, err := as.accountRepository.PromoteAdmins(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if n == 0 || as.cache == nil {
+		return nil
+	}
+	for _, id := range ids {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		if err := as.cache.Del(cacheCtx, fmt.Sprintf("account:%d", id)); err != nil {
+			log.Printf("failed to del cache: %v", err)
+		}
+		cancel()
+	}
+	return nil
+}
+
+// 临时封禁账号：清空 token，写入 Redis 标记，到期后自动解除
+func (as *AccountService) Suspend(ctx context.Context, accountID uint, until time.Time, reason string) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return ErrInvalidSuspension
+	}
+	if err := as.accountRepository.SetSuspension(ctx, accountID, &until, strings.TrimSpace(reason)); err != nil {
+		return err
+	}
+	if as.cache != nil {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if err := as.cache.Del(cacheCtx, fmt.Sprintf("account:%d", accountID)); err != nil {
+			log.Printf("failed to del cache: %v", err)
+		}
+		if err := as.cache.SetBytes(cacheCtx, SuspendedCacheKey(accountID), []byte(reason), ttl); err != nil {
+			log.Printf("failed to set cache: %v", err)
+		}
+	}
+	return nil
+}
+
+func (as *AccountService) Unsuspend(ctx context.Context, accountID uint) error {
+	if err := as.accountRepository.SetSuspension(ctx, accountID, nil, ""); err != nil {
+		return err
+	}
+	if as.cache != nil {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if err := as.cache.Del(cacheCtx, SuspendedCacheKey(accountID)); err != nil {
+			log.Printf("failed to del cache: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package admin
+
+type AccountIDRequest struct {
+	AccountID uint `json:"account_id"`
+}
+
+type SetRoleRequest struct {
+	AccountID uint   `json:"account_id"`
+	Role      string `json:"role"`
+}
+
+type VideoIDRequest struct {
+	VideoID uint `json:"video_id"`
+}
+
+type StatsResponse struct {
+	Accounts          int64 `json:"accounts"`
+	BannedAccounts    int64 `json:"banned_accounts"`
+	SuspendedAccounts int64 `json:"suspended_accounts"`
+	Videos            int64 `json:"videos"`
+	HiddenVideos      int64 `json:"hidden_videos"`
+	Comments          int64 `json:"comments"`
+	Likes             int64 `json:"likes"`
+	Follows           int64 `json:"follows"`
+	PendingReports    int64 `json:"pending_reports"`
+}
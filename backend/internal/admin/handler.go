@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AdminHandler struct {
+	service *AdminService
+}
+
+func NewAdminHandler(service *AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) BanAccount(c *gin.Context) {
+	h.setBanned(c, true)
+}
+
+func (h *AdminHandler) UnbanAccount(c *gin.Context) {
+	h.setBanned(c, false)
+}
+
+func (h *AdminHandler) setBanned(c *gin.Context, banned bool) {
+	var req AccountIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id is required"})
+		return
+	}
+	operatorID, operatorRole, err := operator(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.SetBanned(c.Request.Context(), operatorID, operatorRole, req.AccountID, banned); err != nil {
+		writeError(c, err)
+		return
+	}
+	if banned {
+		c.JSON(http.StatusOK, gin.H{"message": "account banned"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "account unbanned"})
+}
+
+func (h *AdminHandler) SuspendAccount(c *gin.Context) {
+	var req account.SuspendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id is required"})
+		return
+	}
+	if req.Until <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until is required"})
+		return
+	}
+	operatorID, operatorRole, err := operator(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Suspend(c.Request.Context(), operatorID, operatorRole, req.AccountID, time.Unix(req.Until, 0), req.Reason); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "account suspended"})
+}
+
+func (h *AdminHandler) UnsuspendAccount(c *gin.Context) {
+	var req AccountIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id is required"})
+		return
+	}
+	operatorID, operatorRole, err := operator(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Unsuspend(c.Request.Context(), operatorID, operatorRole, req.AccountID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "account unsuspended"})
+}
+
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id is required"})
+		return
+	}
+	operatorID, operatorRole, err := operator(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.SetRole(c.Request.Context(), operatorID, operatorRole, req.AccountID, req.Role); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "role updated"})
+}
+
+func (h *AdminHandler) TakedownVideo(c *gin.Context) {
+	h.setVideoHidden(c, true)
+}
+
+func (h *AdminHandler) RestoreVideo(c *gin.Context) {
+	h.setVideoHidden(c, false)
+}
+
+func (h *AdminHandler) setVideoHidden(c *gin.Context, hidden bool) {
+	var req VideoIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.VideoID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "video_id is required"})
+		return
+	}
+	if err := h.service.SetVideoHidden(c.Request.Context(), req.VideoID, hidden); err != nil {
+		writeError(c, err)
+		return
+	}
+	if hidden {
+		c.JSON(http.StatusOK, gin.H{"message": "video taken down"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "video restored"})
+}
+
+func (h *AdminHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func operator(c *gin.Context) (uint, string, error) {
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		return 0, "", err
+	}
+	role, err := jwt.GetRole(c)
+	if err != nil {
+		return 0, "", err
+	}
+	return accountID, role, nil
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrCannotTargetSelf), errors.Is(err, account.ErrInvalidRole), errors.Is(err, account.ErrInvalidSuspension):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/report"
+	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/video"
+
+	"gorm.io/gorm"
+)
+
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+func (r *AdminRepository) Stats(ctx context.Context) (StatsResponse, error) {
+	var stats StatsResponse
+	counts := []struct {
+		model interface{}
+		where string
+		args  []interface{}
+		dest  *int64
+	}{
+		{&account.Account{}, "", nil, &stats.Accounts},
+		{&account.Account{}, "banned = ?", []interface{}{true}, &stats.BannedAccounts},
+		{&account.Account{}, "suspended_until > ?", []interface{}{time.Now()}, &stats.SuspendedAccounts},
+		{&video.Video{}, "", nil, &stats.Videos},
+		{&video.Video{}, "hidden = ?", []interface{}{true}, &stats.HiddenVideos},
+		{&video.Comment{}, "", nil, &stats.Comments},
+		{&video.Like{}, "", nil, &stats.Likes},
+		{&social.Social{}, "", nil, &stats.Follows},
+		{&report.Report{}, "status = ?", []interface{}{report.StatusPending}, &stats.PendingReports},
+	}
+	for _, c := range counts {
+		query := r.db.WithContext(ctx).Model(c.model)
+		if c.where != "" {
+			query = query.Where(c.where, c.args...)
+		}
+		if err := query.Count(c.dest).Error; err != nil {
+			return StatsResponse{}, err
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/video"
+)
+
+var (
+	ErrPermissionDenied = account.ErrPermissionDenied
+	ErrCannotTargetSelf = account.ErrCannotTargetSelf
+)
+
+type AdminService struct {
+	repo           *AdminRepository
+	accountService *account.AccountService
+	videoService   *video.VideoService
+}
+
+func NewAdminService(repo *AdminRepository, accountService *account.AccountService, videoService *video.VideoService) *AdminService {
+	return &AdminService{repo: repo, accountService: accountService, videoService: videoService}
+}
+
+func (s *AdminService) SetBanned(ctx context.Context, operatorID uint, operatorRole string, accountID uint, banned bool) error {
+	if err := s.checkOperator(ctx, operatorID, operatorRole, accountID); err != nil {
+		return err
+	}
+	return s.accountService.SetBanned(ctx, accountID, banned)
+}
+
+func (s *AdminService) Suspend(ctx context.Context, operatorID uint, operatorRole string, accountID uint, until time.Time, reason string) error {
+	if err := s.checkOperator(ctx, operatorID, operatorRole, accountID); err != nil {
+		return err
+	}
+	return s.accountService.Suspend(ctx, accountID, until, reason)
+}
+
+func (s *AdminService) Unsuspend(ctx context.Context, operatorID uint, operatorRole string, accountID uint) error {
+	if err := s.checkOperator(ctx, operatorID, operatorRole, accountID); err != nil {
+		return err
+	}
+	return s.accountService.Unsuspend(ctx, accountID)
+}
+
+func (s *AdminService) SetRole(ctx context.Context, operatorID uint, operatorRole string, accountID uint, role string) error {
+	if err := s.checkOperator(ctx, operatorID, operatorRole, accountID); err != nil {
+		return err
+	}
+	if account.RoleRank(role) > account.RoleRank(operatorRole) {
+		return ErrPermissionDenied
+	}
+	return s.accountService.SetRole(ctx, accountID, role)
+}
+
+func (s *AdminService) SetVideoHidden(ctx context.Context, videoID uint, hidden bool) error {
+	return s.videoService.SetHidden(ctx, videoID, hidden)
+}
+
+func (s *AdminService) Stats(ctx context.Context) (StatsResponse, error) {
+	return s.repo.Stats(ctx)
+}
+
+// 只能操作角色低于自己的账号
+func (s *AdminService) checkOperator(ctx context.Context, operatorID uint, operatorRole string, accountID uint) error {
+	return s.accountService.CheckOperator(ctx, operatorID, operatorRole, accountID)
+}
@@ -20,15 +20,17 @@ func jwtSecret() []byte {
 type Claims struct {
 	AccountID uint   `json:"account_id"`
 	Username  string `json:"username"`
+	Role      string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(accountID uint, username string) (string, error) {
+func GenerateToken(accountID uint, username string, role string) (string, error) {
 	now := time.Now()
 
 	claims := Claims{
 		AccountID: accountID,
 		Username:  username,
+		Role:      role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(now),
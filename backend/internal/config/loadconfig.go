@@ -11,6 +11,12 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	Redis    RedisConfig    `yaml:"redis"`
 	RabbitMQ RabbitMQConfig `yaml:"rabbitmq"`
+	Report   ReportConfig   `yaml:"report"`
+	Admin    AdminConfig    `yaml:"admin"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Media    MediaConfig    `yaml:"media"`
+	Quota    QuotaConfig    `yaml:"quota"`
+	Play     PlayConfig     `yaml:"play"`
 }
 
 type ServerConfig struct {
@@ -39,6 +45,79 @@ type RabbitMQConfig struct {
 	Password string `yaml:"password"`
 }
 
+type ReportConfig struct {
+	// 同一目标累计多少条待处理举报后自动隐藏，<=0 表示不自动隐藏
+	HideThreshold int `yaml:"hide_threshold"`
+}
+
+// 启动时提升为 admin 角色的账号，用于初始化管理员；已存在管理员时不再生效
+type AdminConfig struct {
+	AccountIDs []uint `yaml:"account_ids"`
+}
+
+type StorageConfig struct {
+	// local | s3
+	Driver string             `yaml:"driver"`
+	Local  LocalStorageConfig `yaml:"local"`
+	S3     S3StorageConfig    `yaml:"s3"`
+	// 预签名地址有效期(秒)
+	PresignTTLSeconds int `yaml:"presign_ttl_seconds"`
+}
+
+type LocalStorageConfig struct {
+	Root    string `yaml:"root"`
+	BaseURL string `yaml:"base_url"`
+	// 本地预签名地址的 HMAC 密钥
+	Secret string `yaml:"secret"`
+}
+
+type S3StorageConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	// MinIO 等需要 path-style 访问
+	PathStyle bool `yaml:"path_style"`
+	// CDN 或公开访问域名，为空时使用 endpoint
+	PublicBaseURL string `yaml:"public_base_url"`
+}
+
+type MediaConfig struct {
+	// 媒体地址的域名前缀，例如 https://cdn.example.com，为空时输出 /media/... 相对地址
+	PublicBaseURL string `yaml:"public_base_url"`
+	// 媒体地址签名的 HMAC 密钥
+	SigningSecret string `yaml:"signing_secret"`
+	// 签名地址有效期(秒)
+	URLTTLSeconds int `yaml:"url_ttl_seconds"`
+	// 上传后超过该时长(小时)仍未被视频引用的文件视为孤儿文件
+	OrphanGraceHours int `yaml:"orphan_grace_hours"`
+	// worker 清理孤儿文件的间隔(分钟)
+	OrphanSweepMinutes int `yaml:"orphan_sweep_minutes"`
+	// HLS 分片目标时长(秒)，实际在关键帧处切分
+	HLSSegmentSeconds int `yaml:"hls_segment_seconds"`
+}
+
+// 每个账号的上传配额，<=0 表示不限制
+type QuotaConfig struct {
+	// 上传文件总大小(MB)
+	StorageMB int64 `yaml:"storage_mb"`
+	// 每天上传文件数（视频和封面合计）
+	DailyUploads int64 `yaml:"daily_uploads"`
+	// 每天发布视频数
+	DailyPublishes int64 `yaml:"daily_publishes"`
+}
+
+// 播放上报与播放量统计
+type PlayConfig struct {
+	// 同一观众在该时长(分钟)内重复播放只计一次
+	DedupWindowMinutes int `yaml:"dedup_window_minutes"`
+	// 观看进度达到该百分比视为完播
+	CompletionPercent int `yaml:"completion_percent"`
+	// worker 把播放统计写回 MySQL 的间隔(秒)
+	FlushSeconds int `yaml:"flush_seconds"`
+}
+
 func Load(filename string) (Config, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
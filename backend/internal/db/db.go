@@ -3,6 +3,7 @@ package db
 import (
 	"feedsystem_video_go/internal/account"
 	"feedsystem_video_go/internal/config"
+	"feedsystem_video_go/internal/report"
 	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"fmt"
@@ -24,7 +25,72 @@ func NewDB(dbcfg config.DatabaseConfig) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&account.Account{}, &video.Video{}, &video.Like{}, &video.Comment{}, &social.Social{})
+	if err := renameURLColumns(db); err != nil {
+		return err
+	}
+	hadStatus := db.Migrator().HasColumn(&video.MediaObject{}, "status")
+	hadCounters := db.Migrator().HasColumn(&account.Account{}, account.CounterFollowers)
+	if err := db.AutoMigrate(&account.Account{}, &video.Video{}, &video.Like{}, &video.Comment{}, &video.UploadSession{}, &video.UploadChunk{}, &video.MediaObject{}, &video.MediaGrant{}, &video.WatchHistory{}, &video.WatchHistoryClear{}, &video.Collection{}, &video.CollectionItem{}, &video.Series{}, &video.SeriesEpisode{}, &social.Social{}, &report.Report{}); err != nil {
+		return err
+	}
+	for _, column := range []string{"status", "linked_at"} {
+		if !db.Migrator().HasColumn(&video.MediaObject{}, column) {
+			return fmt.Errorf("media_objects.%s is missing after migration", column)
+		}
+	}
+	// 新增 status 列时，已被引用的旧对象标记为 linked
+	if !hadStatus {
+		if err := db.Model(&video.MediaObject{}).
+			Where("ref_count > 0").
+			Update("status", video.MediaStatusLinked).Error; err != nil {
+			return err
+		}
+	}
+	// 新增计数列时按现有数据统计一次，之后由关注/点赞/发布流程增量维护
+	if !hadCounters {
+		return backfillAccountCounters(db)
+	}
+	return nil
+}
+
+func backfillAccountCounters(db *gorm.DB) error {
+	followers := db.Model(&social.Social{}).Select("COUNT(*)").Where("socials.vlogger_id = accounts.id")
+	following := db.Model(&social.Social{}).Select("COUNT(*)").Where("socials.follower_id = accounts.id")
+	likes := db.Model(&video.Like{}).Select("COUNT(*)").
+		Joins("JOIN videos ON videos.id = likes.video_id").
+		Where("videos.author_id = accounts.id")
+	videos := db.Model(&video.Video{}).Select("COUNT(*)").
+		Where("videos.author_id = accounts.id AND videos.status = ?", video.StatusPublished)
+	return db.Session(&gorm.Session{AllowGlobalUpdate: true}).
+		Model(&account.Account{}).
+		UpdateColumns(map[string]interface{}{
+			account.CounterFollowers:     followers,
+			account.CounterFollowing:     following,
+			account.CounterLikesReceived: likes,
+			account.CounterVideos:        videos,
+		}).Error
+}
+
+// 媒体字段改为保存存储 key：旧的 *_url 列原地改名为 *_key，数据由 video.MigrateMediaKeys 转换
+func renameURLColumns(db *gorm.DB) error {
+	m := db.Migrator()
+	renames := []struct {
+		model    interface{}
+		from, to string
+	}{
+		{&video.Video{}, "play_url", "play_key"},
+		{&video.Video{}, "cover_url", "cover_key"},
+		{&video.UploadSession{}, "play_url", "play_key"},
+	}
+	for _, r := range renames {
+		if !m.HasTable(r.model) || !m.HasColumn(r.model, r.from) || m.HasColumn(r.model, r.to) {
+			continue
+		}
+		if err := m.RenameColumn(r.model, r.from, r.to); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func CloseDB(db *gorm.DB) error {
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"feedsystem_video_go/internal/video"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// media_repo 和迁移回填直接按列名读写这些列，模型中缺失时 AutoMigrate 不会建列，运行时才报 Unknown column
+func TestMediaObjectColumns(t *testing.T) {
+	s, err := schema.Parse(&video.MediaObject{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	for _, column := range []string{"object_key", "ref_count", "status", "linked_at", "reused_at", "hls_status", "hls_key"} {
+		if s.LookUpField(column) == nil {
+			t.Errorf("media_objects.%s has no model field", column)
+		}
+	}
+	if f := s.LookUpField("status"); f != nil && (!f.NotNull || f.DefaultValue != video.MediaStatusPending) {
+		t.Errorf("status: not null = %v, default = %q, want not null default %q", f.NotNull, f.DefaultValue, video.MediaStatusPending)
+	}
+}
@@ -5,6 +5,8 @@ import "time"
 type FeedAuthor struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
+	// 观众与作者的关系（none/following/followed-by/mutual/blocked），未登录或作者是观众本人时不返回
+	Relation string `json:"relation,omitempty"`
 }
 
 type FeedVideoItem struct {
@@ -13,10 +15,18 @@ type FeedVideoItem struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description,omitempty"`
 	PlayURL     string     `json:"play_url"`
-	CoverURL    string     `json:"cover_url"`
-	CreateTime  int64      `json:"create_time"`
-	LikesCount  int64      `json:"likes_count"`
-	IsLiked     bool       `json:"is_liked"`
+	// HLS 打包完成后 play_url 为播放列表，mp4_url 为原始 MP4
+	MP4URL   string `json:"mp4_url,omitempty"`
+	CoverURL string `json:"cover_url"`
+	// 按宽度标签（720w/360w/120w）给出的封面缩略图地址
+	CoverVariants map[string]string `json:"cover_variants,omitempty"`
+	CreateTime    int64             `json:"create_time"`
+	LikesCount    int64             `json:"likes_count"`
+	CollectCount  int64             `json:"collect_count"`
+	ViewsCount    int64             `json:"views_count"`
+	// 当前登录观众的续播位置(毫秒)
+	ResumePosition int64 `json:"resume_position,omitempty"`
+	IsLiked        bool  `json:"is_liked"`
 }
 
 type ListLatestRequest struct {
@@ -80,3 +90,31 @@ type ListByPopularityResponse struct {
 	NextLatestBefore     *time.Time `json:"next_latest_before,omitempty"`
 	NextLatestIDBefore   *uint      `json:"next_latest_id_before,omitempty"`
 }
+
+const (
+	AuthorSortNewest      = "newest"
+	AuthorSortMostLiked   = "most_liked"
+	AuthorSortMostPopular = "most_popular"
+)
+
+type ListByAuthorRequest struct {
+	AuthorID uint `json:"author_id"`
+	// newest（默认）/ most_liked / most_popular，指定 series_id 时按剧集顺序
+	Sort     string `json:"sort"`
+	SeriesID uint   `json:"series_id,omitempty"`
+	Limit    int    `json:"limit"`
+	// 上一页返回的 next_cursor，第一页不传
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// AuthorCursor 作者视频列表的分页位置：按时间排序时 Value 为毫秒时间戳，按点赞/热度排序时为对应计数，合集为剧集位置
+type AuthorCursor struct {
+	Value int64
+	ID    uint
+}
+
+type ListByAuthorResponse struct {
+	VideoList  []FeedVideoItem `json:"video_list"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	HasMore    bool            `json:"has_more"`
+}
@@ -162,3 +162,41 @@ func (f *FeedHandler) ListByPopularity(c *gin.Context) {
 	}
 	c.JSON(200, resp)
 }
+
+func (f *FeedHandler) ListByAuthor(c *gin.Context) {
+	var req ListByAuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AuthorID == 0 {
+		c.JSON(400, gin.H{"error": "author_id is required"})
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = 10
+	}
+	switch req.Sort {
+	case "":
+		req.Sort = AuthorSortNewest
+	case AuthorSortNewest, AuthorSortMostLiked, AuthorSortMostPopular:
+	default:
+		c.JSON(400, gin.H{"error": "sort must be newest, most_liked or most_popular"})
+		return
+	}
+	cursor, err := ParseAuthorCursor(req.Cursor)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	resp, err := f.service.ListByAuthor(c.Request.Context(), req, cursor, viewerAccountID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
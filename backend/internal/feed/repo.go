@@ -20,6 +20,7 @@ func NewFeedRepository(db *gorm.DB) *FeedRepository {
 func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, latestBefore time.Time) ([]*video.Video, error) {
 	var videos []*video.Video
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Public).
 		Order("create_time DESC")
 	if !latestBefore.IsZero() {
 		query = query.Where("create_time < ?", latestBefore)
@@ -33,6 +34,7 @@ func (repo *FeedRepository) ListLatest(ctx context.Context, limit int, latestBef
 func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit int, cursor *LikesCountCursor) ([]*video.Video, error) {
 	var videos []*video.Video
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Public).
 		Order("likes_count DESC, id DESC")
 
 	if cursor != nil {
@@ -52,6 +54,8 @@ func (repo *FeedRepository) ListLikesCountWithCursor(ctx context.Context, limit
 func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, viewerAccountID uint, latestBefore time.Time) ([]*video.Video, error) {
 	var videos []*video.Video
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Listed).
+		Where("videos.visibility IN ?", []string{video.VisibilityPublic, video.VisibilityFollowers}).
 		Order("create_time DESC")
 	if viewerAccountID > 0 {
 		followingSubQuery := repo.db.WithContext(ctx).
@@ -72,6 +76,7 @@ func (repo *FeedRepository) ListByFollowing(ctx context.Context, limit int, view
 func (repo *FeedRepository) ListByPopularity(ctx context.Context, limit int, popularityBefore int64, timeBefore time.Time, idBefore uint) ([]*video.Video, error) {
 	var videos []*video.Video
 	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Public).
 		Order("popularity DESC, create_time DESC, id DESC")
 
 	// 只有当游标完整提供时才加过滤（popularity 允许为 0）
@@ -96,8 +101,64 @@ func (repo *FeedRepository) GetByIDs(ctx context.Context, ids []uint) ([]*video.
 		return videos, nil
 	}
 	if err := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Public).
 		Where("id IN ?", ids).Find(&videos).Error; err != nil {
 		return nil, err
 	}
 	return videos, nil
 }
+
+// ListByAuthor 作者主页视频列表；visibilities 为空表示不过滤（作者本人），seriesID 不为 0 时按剧集顺序
+func (repo *FeedRepository) ListByAuthor(ctx context.Context, authorID uint, visibilities []string, sort string, seriesID uint, cursor *AuthorCursor, limit int) ([]*video.Video, error) {
+	var videos []*video.Video
+	query := repo.db.WithContext(ctx).Model(&video.Video{}).
+		Scopes(video.Listed).
+		Where("videos.author_id = ?", authorID)
+	if len(visibilities) > 0 {
+		query = query.Where("videos.visibility IN ?", visibilities)
+	}
+
+	if seriesID > 0 {
+		query = query.
+			Joins("JOIN series_episodes ON series_episodes.video_id = videos.id").
+			Where("series_episodes.series_id = ?", seriesID).
+			Order("series_episodes.position ASC")
+		if cursor != nil {
+			query = query.Where("series_episodes.position > ?", cursor.Value)
+		}
+	} else {
+		column := "videos.create_time"
+		switch sort {
+		case AuthorSortMostLiked:
+			column = "videos.likes_count"
+		case AuthorSortMostPopular:
+			column = "videos.popularity"
+		}
+		query = query.Order(column + " DESC, videos.id DESC")
+		if cursor != nil {
+			var value interface{} = cursor.Value
+			if column == "videos.create_time" {
+				value = time.UnixMilli(cursor.Value)
+			}
+			query = query.Where(
+				"("+column+" < ?) OR ("+column+" = ? AND videos.id < ?)",
+				value,
+				value, cursor.ID,
+			)
+		}
+	}
+
+	if err := query.Limit(limit).Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+func (repo *FeedRepository) GetSeriesPosition(ctx context.Context, seriesID, videoID uint) (int64, error) {
+	var position int64
+	err := repo.db.WithContext(ctx).Model(&video.SeriesEpisode{}).
+		Where("series_id = ? AND video_id = ?", seriesID, videoID).
+		Select("position").
+		Scan(&position).Error
+	return position, err
+}
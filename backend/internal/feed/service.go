@@ -3,10 +3,14 @@ package feed
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/media"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/social"
 	"feedsystem_video_go/internal/video"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,10 +19,17 @@ type FeedService struct {
 	likeRepo *video.LikeRepository
 	cache    *rediscache.Client
 	cacheTTL time.Duration
+	signer   *media.URLSigner
+	history  *video.HistoryService
+	videos   *video.VideoService
+	social   *social.SocialService
 }
 
-func NewFeedService(repo *FeedRepository, likeRepo *video.LikeRepository, cache *rediscache.Client) *FeedService {
-	return &FeedService{repo: repo, likeRepo: likeRepo, cache: cache, cacheTTL: 5 * time.Second}
+// 作者主页第一页缓存，视频发布/更新/删除时随其他流缓存一起失效
+const authorCacheTTL = 30 * time.Second
+
+func NewFeedService(repo *FeedRepository, likeRepo *video.LikeRepository, cache *rediscache.Client, signer *media.URLSigner, history *video.HistoryService, videos *video.VideoService, socialService *social.SocialService) *FeedService {
+	return &FeedService{repo: repo, likeRepo: likeRepo, cache: cache, cacheTTL: 5 * time.Second, signer: signer, history: history, videos: videos, social: socialService}
 }
 
 // 查询最新视频
@@ -292,11 +303,12 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 				if err != nil {
 					return ListByPopularityResponse{}, err
 				}
+				// 不可见的成员会被过滤，游标按消耗的快照位置前进
 				resp := ListByPopularityResponse{
 					VideoList:  items,
 					AsOf:       asOf.Unix(),
-					NextOffset: offset + len(items),
-					HasMore:    len(items) == limit,
+					NextOffset: offset + len(members),
+					HasMore:    len(members) == limit,
 				}
 				if len(ordered) > 0 {
 					last := ordered[len(ordered)-1]
@@ -338,6 +350,109 @@ func (f *FeedService) ListByPopularity(ctx context.Context, limit int, reqAsOf i
 	return resp, nil
 }
 
+// 作者主页视频列表，只有第一页走缓存；缓存的是视频行，is_liked 等按观众现算
+func (f *FeedService) ListByAuthor(ctx context.Context, req ListByAuthorRequest, cursor *AuthorCursor, viewerAccountID uint) (ListByAuthorResponse, error) {
+	visibilities, err := f.videos.AuthorVisibilities(ctx, req.AuthorID, viewerAccountID)
+	if err != nil {
+		return ListByAuthorResponse{}, err
+	}
+
+	var cacheKey string
+	if cursor == nil && f.cache != nil {
+		scope := "public"
+		if visibilities == nil {
+			scope = "all"
+		} else if len(visibilities) > 1 {
+			scope = "followers"
+		}
+		cacheKey = fmt.Sprintf("feed:listByAuthor:author=%d:scope=%s:sort=%s:series=%d:limit=%d", req.AuthorID, scope, req.Sort, req.SeriesID, req.Limit)
+	}
+
+	var videos []*video.Video
+	cached := false
+	if cacheKey != "" {
+		cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		b, err := f.cache.GetBytes(cacheCtx, cacheKey)
+		cancel()
+		if err == nil && json.Unmarshal(b, &videos) == nil {
+			cached = true
+		}
+	}
+	if !cached {
+		// 多取一条判断是否还有下一页
+		videos, err = f.repo.ListByAuthor(ctx, req.AuthorID, visibilities, req.Sort, req.SeriesID, cursor, req.Limit+1)
+		if err != nil {
+			return ListByAuthorResponse{}, err
+		}
+		if cacheKey != "" {
+			if b, err := json.Marshal(videos); err == nil {
+				cacheCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				_ = f.cache.SetBytes(cacheCtx, cacheKey, b, authorCacheTTL)
+				cancel()
+			}
+		}
+	}
+
+	resp := ListByAuthorResponse{}
+	if len(videos) > req.Limit {
+		videos = videos[:req.Limit]
+		resp.HasMore = true
+	}
+	if resp.VideoList, err = f.buildFeedVideos(ctx, videos, viewerAccountID); err != nil {
+		return ListByAuthorResponse{}, err
+	}
+	if resp.HasMore {
+		last := videos[len(videos)-1]
+		next := AuthorCursor{ID: last.ID}
+		switch {
+		case req.SeriesID > 0:
+			if next.Value, err = f.repo.GetSeriesPosition(ctx, req.SeriesID, last.ID); err != nil {
+				return ListByAuthorResponse{}, err
+			}
+		case req.Sort == AuthorSortMostLiked:
+			next.Value = last.LikesCount
+		case req.Sort == AuthorSortMostPopular:
+			next.Value = last.Popularity
+		default:
+			next.Value = last.CreateTime.UnixMilli()
+		}
+		resp.NextCursor = FormatAuthorCursor(next)
+	}
+	return resp, nil
+}
+
+// 游标格式为 "<value>_<id>"
+func FormatAuthorCursor(c AuthorCursor) string {
+	return strconv.FormatInt(c.Value, 10) + "_" + strconv.FormatUint(uint64(c.ID), 10)
+}
+
+func ParseAuthorCursor(raw string) (*AuthorCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	valuePart, idPart, ok := strings.Cut(raw, "_")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+	value, err := strconv.ParseInt(valuePart, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &AuthorCursor{Value: value, ID: uint(id)}, nil
+}
+
+// playbackKey HLS 打包完成的视频播放播放列表，否则播放原始 MP4
+func playbackKey(v *video.Video) string {
+	if v.HLSStatus == video.HLSStatusReady && v.HLSKey != "" {
+		return v.HLSKey
+	}
+	return v.PlayKey
+}
+
 func (f *FeedService) buildFeedVideos(ctx context.Context, videos []*video.Video, viewerAccountID uint) ([]FeedVideoItem, error) {
 	feedVideos := make([]FeedVideoItem, 0, len(videos))
 	videoIDs := make([]uint, len(videos))
@@ -348,17 +463,42 @@ func (f *FeedService) buildFeedVideos(ctx context.Context, videos []*video.Video
 	if err != nil {
 		return nil, err
 	}
+	positions := map[uint]int64{}
+	if f.history != nil {
+		if positions, err = f.history.ResumePositions(ctx, viewerAccountID, videoIDs); err != nil {
+			return nil, err
+		}
+	}
+	relations := map[uint]string{}
+	if f.social != nil && viewerAccountID != 0 {
+		authorIDs := make([]uint, 0, len(videos))
+		for _, v := range videos {
+			authorIDs = append(authorIDs, v.AuthorID)
+		}
+		if relations, err = f.social.Relations(ctx, viewerAccountID, authorIDs); err != nil {
+			return nil, err
+		}
+	}
 	for _, video := range videos {
+		author := FeedAuthor{ID: video.AuthorID, Username: video.Username}
+		if video.AuthorID != viewerAccountID {
+			author.Relation = relations[video.AuthorID]
+		}
 		feedVideos = append(feedVideos, FeedVideoItem{
-			ID:          video.ID,
-			Author:      FeedAuthor{ID: video.AuthorID, Username: video.Username},
-			Title:       video.Title,
-			Description: video.Description,
-			PlayURL:     video.PlayURL,
-			CoverURL:    video.CoverURL,
-			CreateTime:  video.CreateTime.Unix(),
-			LikesCount:  video.LikesCount,
-			IsLiked:     likedMap[video.ID],
+			ID:             video.ID,
+			Author:         author,
+			Title:          video.Title,
+			Description:    video.Description,
+			PlayURL:        f.signer.Sign(playbackKey(video)),
+			MP4URL:         f.signer.Sign(video.PlayKey),
+			CoverURL:       f.signer.Sign(video.CoverKey),
+			CoverVariants:  f.signer.SignVariants(video.CoverVariantKeys),
+			CreateTime:     video.CreateTime.Unix(),
+			LikesCount:     video.LikesCount,
+			CollectCount:   video.CollectCount,
+			ViewsCount:     video.ViewsCount,
+			IsLiked:        likedMap[video.ID],
+			ResumePosition: positions[video.ID],
 		})
 	}
 	return feedVideos, nil
@@ -2,21 +2,32 @@ package http
 
 import (
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/admin"
+	"feedsystem_video_go/internal/config"
 	"feedsystem_video_go/internal/feed"
+	"feedsystem_video_go/internal/media"
 	"feedsystem_video_go/internal/middleware/jwt"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/report"
 	"feedsystem_video_go/internal/social"
+	"feedsystem_video_go/internal/storage"
 	"feedsystem_video_go/internal/video"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *gin.Engine {
+func SetRouter(cfg *config.Config, db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ, store storage.Backend) *gin.Engine {
 	r := gin.Default()
-	r.Static("/static", "./.run/uploads")
+	// storage: 仅本地存储需要由 API 提供预签名直传
+	if local, ok := store.(*storage.Local); ok {
+		localHandler := storage.NewLocalHandler(local, 200<<20)
+		r.PUT("/storage/object/*key", localHandler.PutObject)
+		r.GET("/storage/object/*key", localHandler.GetObject)
+	}
 	// account
 	accountRepository := account.NewAccountRepository(db)
 	accountService := account.NewAccountService(accountRepository, cache)
@@ -34,6 +45,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	{
 		protectedAccountGroup.POST("/logout", accountHandler.Logout)
 		protectedAccountGroup.POST("/rename", accountHandler.Rename)
+		protectedAccountGroup.POST("/setLikesPublic", accountHandler.SetLikesPublic)
 	}
 	// video
 	videoRepository := video.NewVideoRepository(db)
@@ -42,19 +54,132 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		log.Printf("PopularityMQ init failed (mq disabled): %v", err)
 		popularityMQ = nil
 	}
-	videoService := video.NewVideoService(videoRepository, cache, popularityMQ)
-	videoHandler := video.NewVideoHandler(videoService, accountService)
+	videoMQ, err := rabbitmq.NewVideoMQ(rmq)
+	if err != nil {
+		log.Printf("VideoMQ init failed (mq disabled): %v", err)
+		videoMQ = nil
+	}
+	historyMQ, err := rabbitmq.NewHistoryMQ(rmq)
+	if err != nil {
+		log.Printf("HistoryMQ init failed (mq disabled): %v", err)
+		historyMQ = nil
+	}
+	likeRepository := video.NewLikeRepository(db)
+	commentRepository := video.NewCommentRepository(db)
+	socialRepository := social.NewSocialRepository(db)
+	urlSigner := media.NewURLSigner(cfg.Media.PublicBaseURL, cfg.Media.SigningSecret, time.Duration(cfg.Media.URLTTLSeconds)*time.Second)
+	mediaRepository := video.NewMediaRepository(db)
+	mediaService := video.NewMediaService(mediaRepository, store, urlSigner)
+	// profile: 头像复用封面上传，先上传封面再把 key 写入资料
+	profileHandler := account.NewProfileHandler(account.NewProfileService(accountRepository, mediaService))
+	accountGroup.POST("/getProfile", jwt.SoftJWTAuth(accountRepository, cache), profileHandler.Get)
+	protectedAccountGroup.POST("/profile", profileHandler.Update)
+	hlsPackager := video.NewHLSPackager(mediaService, videoRepository, time.Duration(cfg.Media.HLSSegmentSeconds)*time.Second)
+	seriesRepository := video.NewSeriesRepository(db)
+	videoCleaner := video.NewVideoCleaner(videoRepository, likeRepository, commentRepository, socialRepository, cache, mediaService, hlsPackager, seriesRepository)
+	videoService := video.NewVideoService(videoRepository, cache, popularityMQ, videoMQ, videoCleaner, socialRepository, mediaService)
+	seriesService := video.NewSeriesService(seriesRepository, videoRepository, videoService, mediaService)
+	historyService := video.NewHistoryService(video.NewHistoryRepository(db), videoRepository, mediaService, cache, historyMQ)
+	quotaService := video.NewQuotaService(video.QuotaLimits{
+		StorageBytes:   cfg.Quota.StorageMB << 20,
+		DailyUploads:   cfg.Quota.DailyUploads,
+		DailyPublishes: cfg.Quota.DailyPublishes,
+	}, mediaRepository, videoRepository, cache)
+	videoHandler := video.NewVideoHandler(videoService, accountService, mediaService, store, time.Duration(cfg.Storage.PresignTTLSeconds)*time.Second, quotaService, historyService, seriesService)
+	playService := video.NewPlayService(videoService, videoRepository, cache, popularityMQ, historyService, time.Duration(cfg.Play.DedupWindowMinutes)*time.Minute, cfg.Play.CompletionPercent)
+	playHandler := video.NewPlayHandler(playService)
+	uploadRepository := video.NewUploadRepository(db)
+	uploadService := video.NewUploadService(uploadRepository, mediaService)
+	uploadHandler := video.NewUploadHandler(uploadService, quotaService)
+	// media: 上传文件统一经过权限校验后输出，本地存储的公开地址前缀也由它处理
+	mediaHandler := video.NewMediaHandler(videoService, mediaService)
+	mediaGroup := r.Group("")
+	mediaGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
+	{
+		mediaGroup.GET("/media/*key", mediaHandler.Serve)
+		mediaGroup.HEAD("/media/*key", mediaHandler.Serve)
+		if local, ok := store.(*storage.Local); ok && local.BasePath() != "/media" {
+			mediaGroup.GET(local.BasePath()+"/*key", mediaHandler.Serve)
+			mediaGroup.HEAD(local.BasePath()+"/*key", mediaHandler.Serve)
+		}
+	}
 	videoGroup := r.Group("/video")
+	videoGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
 	{
-		videoGroup.POST("/listByAuthorID", videoHandler.ListByAuthorID)
 		videoGroup.POST("/getDetail", videoHandler.GetDetail)
+		videoGroup.POST("/presignDownload", videoHandler.PresignDownload)
+		videoGroup.POST("/reportPlay", playHandler.ReportPlay)
 	}
 	protectedVideoGroup := videoGroup.Group("")
 	protectedVideoGroup.Use(jwt.JWTAuth(accountRepository, cache))
 	{
 		protectedVideoGroup.POST("/uploadVideo", videoHandler.UploadVideo)
 		protectedVideoGroup.POST("/uploadCover", videoHandler.UploadCover)
+		protectedVideoGroup.POST("/upload/presign", videoHandler.PresignUpload)
+		protectedVideoGroup.POST("/quota", videoHandler.Quota)
+		protectedVideoGroup.POST("/upload/init", uploadHandler.Init)
+		protectedVideoGroup.POST("/upload/chunk", uploadHandler.Chunk)
+		protectedVideoGroup.POST("/upload/status", uploadHandler.Status)
+		protectedVideoGroup.POST("/upload/complete", uploadHandler.Complete)
 		protectedVideoGroup.POST("/publish", videoHandler.PublishVideo)
+		protectedVideoGroup.POST("/update", videoHandler.UpdateVideo)
+		protectedVideoGroup.POST("/delete", videoHandler.DeleteVideo)
+		protectedVideoGroup.POST("/draft/save", videoHandler.SaveDraft)
+		protectedVideoGroup.POST("/draft/list", videoHandler.ListDrafts)
+		protectedVideoGroup.POST("/draft/publish", videoHandler.PublishDraft)
+		protectedVideoGroup.POST("/draft/unschedule", videoHandler.UnscheduleDraft)
+		protectedVideoGroup.POST("/draft/delete", videoHandler.DeleteDraft)
+	}
+	// history
+	historyHandler := video.NewHistoryHandler(historyService)
+	historyGroup := r.Group("/history")
+	historyGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		historyGroup.POST("/list", historyHandler.List)
+		historyGroup.POST("/delete", historyHandler.Delete)
+		historyGroup.POST("/clear", historyHandler.Clear)
+	}
+	// series
+	seriesHandler := video.NewSeriesHandler(seriesService)
+	seriesGroup := r.Group("/series")
+	seriesGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
+	{
+		seriesGroup.POST("/get", seriesHandler.Get)
+		seriesGroup.POST("/listByAuthorID", seriesHandler.ListByAuthor)
+	}
+	protectedSeriesGroup := seriesGroup.Group("")
+	protectedSeriesGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		protectedSeriesGroup.POST("/create", seriesHandler.Create)
+		protectedSeriesGroup.POST("/update", seriesHandler.Update)
+		protectedSeriesGroup.POST("/delete", seriesHandler.Delete)
+		protectedSeriesGroup.POST("/addVideo", seriesHandler.AddVideo)
+		protectedSeriesGroup.POST("/removeVideo", seriesHandler.RemoveVideo)
+		protectedSeriesGroup.POST("/reorder", seriesHandler.Reorder)
+	}
+	// collection
+	collectMQ, err := rabbitmq.NewCollectMQ(rmq)
+	if err != nil {
+		log.Printf("CollectMQ init failed (mq disabled): %v", err)
+		collectMQ = nil
+	}
+	collectionService := video.NewCollectionService(video.NewCollectionRepository(db), videoRepository, videoService, mediaService, collectMQ)
+	collectionHandler := video.NewCollectionHandler(collectionService)
+	collectionGroup := r.Group("/collection")
+	collectionGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
+	{
+		collectionGroup.POST("/list", collectionHandler.List)
+		collectionGroup.POST("/listVideos", collectionHandler.ListVideos)
+	}
+	protectedCollectionGroup := collectionGroup.Group("")
+	protectedCollectionGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		protectedCollectionGroup.POST("/create", collectionHandler.Create)
+		protectedCollectionGroup.POST("/rename", collectionHandler.Rename)
+		protectedCollectionGroup.POST("/setVisibility", collectionHandler.SetVisibility)
+		protectedCollectionGroup.POST("/delete", collectionHandler.Delete)
+		protectedCollectionGroup.POST("/addVideo", collectionHandler.AddVideo)
+		protectedCollectionGroup.POST("/removeVideo", collectionHandler.RemoveVideo)
 	}
 	// like
 	likeMQ, err := rabbitmq.NewLikeMQ(rmq)
@@ -62,10 +187,13 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		log.Printf("LikeMQ init failed (mq disabled): %v", err)
 		likeMQ = nil
 	}
-	likeRepository := video.NewLikeRepository(db)
-	likeService := video.NewLikeService(likeRepository, videoRepository, cache, likeMQ, popularityMQ)
-	likeHandler := video.NewLikeHandler(likeService)
+	likeService := video.NewLikeService(likeRepository, videoRepository, cache, likeMQ, popularityMQ, accountRepository)
+	likeHandler := video.NewLikeHandler(likeService, mediaService)
 	likeGroup := r.Group("/like")
+	likeGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
+	{
+		likeGroup.POST("/listLikedVideos", likeHandler.ListLikedVideos)
+	}
 	protectedLikeGroup := likeGroup.Group("")
 	protectedLikeGroup.Use(jwt.JWTAuth(accountRepository, cache))
 	{
@@ -75,7 +203,6 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		protectedLikeGroup.POST("/listMyLikedVideos", likeHandler.ListMyLikedVideos)
 	}
 	// comment
-	commentRepository := video.NewCommentRepository(db)
 	commentMQ, err := rabbitmq.NewCommentMQ(rmq)
 	if err != nil {
 		log.Printf("CommentMQ init failed (mq disabled): %v", err)
@@ -99,8 +226,7 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 		log.Printf("SocialMQ init failed (mq disabled): %v", err)
 		socialMQ = nil
 	}
-	socialRepository := social.NewSocialRepository(db)
-	socialService := social.NewSocialService(socialRepository, accountRepository, socialMQ)
+	socialService := social.NewSocialService(socialRepository, accountRepository, socialMQ, mediaService)
 	socialHandler := social.NewSocialHandler(socialService)
 	socialGroup := r.Group("/social")
 	protectedSocialGroup := socialGroup.Group("")
@@ -108,13 +234,19 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	{
 		protectedSocialGroup.POST("/follow", socialHandler.Follow)
 		protectedSocialGroup.POST("/unfollow", socialHandler.Unfollow)
+		protectedSocialGroup.POST("/listFollowers", socialHandler.ListFollowers)
+		protectedSocialGroup.POST("/listFollowing", socialHandler.ListFollowing)
+		// 旧接口，一次返回全部
 		protectedSocialGroup.POST("/getAllFollowers", socialHandler.GetAllFollowers)
 		protectedSocialGroup.POST("/getAllVloggers", socialHandler.GetAllVloggers)
+		protectedSocialGroup.POST("/listFriends", socialHandler.ListFriends)
+		protectedSocialGroup.POST("/relation", socialHandler.Relation)
 	}
 	// feed
 	feedRepository := feed.NewFeedRepository(db)
-	feedService := feed.NewFeedService(feedRepository, likeRepository, cache)
+	feedService := feed.NewFeedService(feedRepository, likeRepository, cache, urlSigner, historyService, videoService, socialService)
 	feedHandler := feed.NewFeedHandler(feedService)
+	videoGroup.POST("/listByAuthorID", feedHandler.ListByAuthor)
 	feedGroup := r.Group("/feed")
 	feedGroup.Use(jwt.SoftJWTAuth(accountRepository, cache))
 	{
@@ -127,5 +259,37 @@ func SetRouter(db *gorm.DB, cache *rediscache.Client, rmq *rabbitmq.RabbitMQ) *g
 	{
 		protectedFeedGroup.POST("/listByFollowing", feedHandler.ListByFollowing)
 	}
+	// report
+	reportRepository := report.NewReportRepository(db)
+	reportService := report.NewReportService(reportRepository, accountService, videoService, videoRepository, commentRepository, cfg.Report.HideThreshold)
+	reportHandler := report.NewReportHandler(reportService)
+	reportGroup := r.Group("/report")
+	protectedReportGroup := reportGroup.Group("")
+	protectedReportGroup.Use(jwt.JWTAuth(accountRepository, cache))
+	{
+		protectedReportGroup.POST("/create", reportHandler.CreateReport)
+	}
+	// admin
+	adminRepository := admin.NewAdminRepository(db)
+	adminService := admin.NewAdminService(adminRepository, accountService, videoService)
+	adminHandler := admin.NewAdminHandler(adminService)
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(jwt.JWTAuth(accountRepository, cache), jwt.RequireRole(account.RoleModerator, account.RoleAdmin))
+	{
+		adminGroup.POST("/account/ban", adminHandler.BanAccount)
+		adminGroup.POST("/account/unban", adminHandler.UnbanAccount)
+		adminGroup.POST("/account/suspend", adminHandler.SuspendAccount)
+		adminGroup.POST("/account/unsuspend", adminHandler.UnsuspendAccount)
+		adminGroup.POST("/video/takedown", adminHandler.TakedownVideo)
+		adminGroup.POST("/video/restore", adminHandler.RestoreVideo)
+		adminGroup.POST("/stats", adminHandler.Stats)
+		adminGroup.POST("/report/list", reportHandler.ListReports)
+		adminGroup.POST("/report/resolve", reportHandler.ResolveReport)
+	}
+	superAdminGroup := adminGroup.Group("")
+	superAdminGroup.Use(jwt.RequireRole(account.RoleAdmin))
+	{
+		superAdminGroup.POST("/account/setRole", adminHandler.SetRole)
+	}
 	return r
 }
@@ -0,0 +1,607 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	HLSPlaylistName = "index.m3u8"
+	HLSInitName     = "init.mp4"
+	hlsPrefix       = "hls/"
+)
+
+var ErrHLSUnsupported = errors.New("only H.264/AAC mp4 can be packaged as hls")
+
+// HLSKey HLS 文件与原视频一一对应：hls/<原 key>/<name>，可据此反查原视频
+func HLSKey(playKey, name string) string {
+	return hlsPrefix + playKey + "/" + name
+}
+
+// HLSSource 从 HLS 文件 key 反查原视频 key
+func HLSSource(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, hlsPrefix)
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndexByte(rest, '/')
+	if i <= 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+func IsPlaylist(key string) bool {
+	return strings.EqualFold(path.Ext(key), ".m3u8")
+}
+
+// RewritePlaylist 替换播放列表中的分片地址和 EXT-X-MAP URI，用于给每个分片附加签名
+func RewritePlaylist(data []byte, resolve func(uri string) string) []byte {
+	var b bytes.Buffer
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			if i := strings.Index(line, `URI="`); i >= 0 {
+				start := i + len(`URI="`)
+				if end := strings.IndexByte(line[start:], '"'); end >= 0 {
+					line = line[:start] + resolve(line[start:start+end]) + line[start+end:]
+				}
+			}
+		default:
+			line = resolve(line)
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
+
+type hlsSample struct {
+	offset   int64
+	size     uint32
+	dts      uint64
+	duration uint32
+	cto      int32
+	sync     bool
+}
+
+type hlsTrack struct {
+	id        uint32
+	handler   string
+	timescale uint32
+	codec     string
+	trak      *mp4Box
+	samples   []hlsSample
+}
+
+// PackageHLS 把 H.264/AAC 的 MP4 重新封装为 fMP4 分片（不转码），分片在视频关键帧处切分。
+// emit 依次收到 init.mp4、各个分片和最后的 index.m3u8
+func PackageHLS(r io.ReaderAt, size int64, segmentDuration time.Duration, emit func(name string, data []byte) error) error {
+	if segmentDuration <= 0 {
+		segmentDuration = 6 * time.Second
+	}
+	moov, err := readMoov(r, size)
+	if err != nil {
+		return err
+	}
+	boxes, err := parseBoxTree(moov)
+	if err != nil {
+		return err
+	}
+	var mvhd *mp4Box
+	var video, audio *hlsTrack
+	for _, b := range boxes {
+		switch b.typ {
+		case "mvhd":
+			mvhd = b
+		case "mvex":
+			return fmt.Errorf("%w: already fragmented", ErrHLSUnsupported)
+		case "trak":
+			t, err := parseHLSTrack(b, size)
+			if err != nil {
+				return err
+			}
+			switch {
+			case t == nil:
+			case t.handler == "vide" && video == nil:
+				video = t
+			case t.handler == "soun" && audio == nil:
+				audio = t
+			}
+		}
+	}
+	if mvhd == nil || video == nil || len(video.samples) == 0 {
+		return fmt.Errorf("%w: no video track", ErrInvalidMP4)
+	}
+	if video.codec != "avc1" && video.codec != "avc3" {
+		return fmt.Errorf("%w: video codec %s", ErrHLSUnsupported, video.codec)
+	}
+	tracks := []*hlsTrack{video}
+	if audio != nil && len(audio.samples) > 0 {
+		if audio.codec != "mp4a" {
+			return fmt.Errorf("%w: audio codec %s", ErrHLSUnsupported, audio.codec)
+		}
+		tracks = append(tracks, audio)
+	}
+
+	if err := emit(HLSInitName, buildInitSegment(mvhd, tracks)); err != nil {
+		return err
+	}
+
+	// 按视频关键帧切分，音频按时间跟随
+	vs := video.samples
+	target := uint64(segmentDuration.Seconds() * float64(video.timescale))
+	var (
+		durations []float64
+		seq       uint32
+		start     int
+		audioPos  int
+	)
+	for start < len(vs) {
+		end := start + 1
+		for end < len(vs) && !(vs[end].sync && vs[end].dts-vs[start].dts >= target) {
+			end++
+		}
+		endDts := vs[end-1].dts + uint64(vs[end-1].duration)
+		if end < len(vs) {
+			endDts = vs[end].dts
+		}
+		parts := [][]hlsSample{vs[start:end]}
+		if len(tracks) > 1 {
+			as := audio.samples
+			audioEnd := audioPos
+			for audioEnd < len(as) && (end == len(vs) || as[audioEnd].dts*uint64(video.timescale) < endDts*uint64(audio.timescale)) {
+				audioEnd++
+			}
+			parts = append(parts, as[audioPos:audioEnd])
+			audioPos = audioEnd
+		}
+		seq++
+		data, err := buildMediaSegment(r, seq, tracks, parts)
+		if err != nil {
+			return err
+		}
+		if err := emit(hlsSegmentName(int(seq-1)), data); err != nil {
+			return err
+		}
+		durations = append(durations, float64(endDts-vs[start].dts)/float64(video.timescale))
+		start = end
+	}
+	return emit(HLSPlaylistName, buildPlaylist(durations))
+}
+
+func hlsSegmentName(i int) string {
+	return fmt.Sprintf("seg_%05d.m4s", i)
+}
+
+func buildPlaylist(durations []float64) []byte {
+	maxDur := 0.0
+	for _, d := range durations {
+		maxDur = math.Max(maxDur, d)
+	}
+	var b bytes.Buffer
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(maxDur)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	fmt.Fprintf(&b, "#EXT-X-MAP:URI=\"%s\"\n", HLSInitName)
+	for i, d := range durations {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", d, hlsSegmentName(i))
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.Bytes()
+}
+
+func readMoov(r io.ReaderAt, size int64) ([]byte, error) {
+	var offset int64
+	for offset < size {
+		sr := io.NewSectionReader(r, offset, size-offset)
+		h, err := readBoxHeader(sr, size-offset)
+		if err != nil {
+			return nil, err
+		}
+		if h.typ == "moov" {
+			return readBody(sr, h, maxMoovSize)
+		}
+		offset += h.size
+	}
+	return nil, fmt.Errorf("%w: missing moov", ErrInvalidMP4)
+}
+
+// mp4Box 解析后的 box，容器类 box 保存子节点，其余保存原始内容
+type mp4Box struct {
+	typ      string
+	body     []byte
+	children []*mp4Box
+}
+
+func isContainerBox(typ string) bool {
+	switch typ {
+	case "moov", "trak", "mdia", "minf", "stbl", "edts", "dinf":
+		return true
+	}
+	return false
+}
+
+func parseBoxTree(data []byte) ([]*mp4Box, error) {
+	var boxes []*mp4Box
+	err := eachBox(data, func(typ string, body []byte) error {
+		b := &mp4Box{typ: typ}
+		if isContainerBox(typ) {
+			children, err := parseBoxTree(body)
+			if err != nil {
+				return err
+			}
+			b.children = children
+		} else {
+			b.body = body
+		}
+		boxes = append(boxes, b)
+		return nil
+	})
+	return boxes, err
+}
+
+func (b *mp4Box) child(typ string) *mp4Box {
+	for _, c := range b.children {
+		if c.typ == typ {
+			return c
+		}
+	}
+	return nil
+}
+
+func (b *mp4Box) find(path ...string) *mp4Box {
+	cur := b
+	for _, p := range path {
+		if cur = cur.child(p); cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+func (b *mp4Box) encode() []byte {
+	body := b.body
+	if b.children != nil {
+		var buf bytes.Buffer
+		for _, c := range b.children {
+			buf.Write(c.encode())
+		}
+		body = buf.Bytes()
+	}
+	return makeBox(b.typ, body)
+}
+
+func makeBox(typ string, body []byte) []byte {
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out[:4], uint32(8+len(body)))
+	copy(out[4:8], typ)
+	return append(out, body...)
+}
+
+// fullBox version/flags 头
+func fullBoxHeader(version uint8, flags uint32) []byte {
+	return []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+}
+
+// parseHLSTrack 展开 sample table；非音视频轨道返回 nil
+func parseHLSTrack(trak *mp4Box, fileSize int64) (*hlsTrack, error) {
+	t := &hlsTrack{trak: trak}
+	hdlr := trak.find("mdia", "hdlr")
+	if hdlr == nil || len(hdlr.body) < 12 {
+		return nil, fmt.Errorf("%w: missing hdlr", ErrInvalidMP4)
+	}
+	t.handler = string(hdlr.body[8:12])
+	if t.handler != "vide" && t.handler != "soun" {
+		return nil, nil
+	}
+	tkhd := trak.child("tkhd")
+	if tkhd == nil || len(tkhd.body) < 24 {
+		return nil, fmt.Errorf("%w: missing tkhd", ErrInvalidMP4)
+	}
+	if tkhd.body[0] == 1 {
+		if len(tkhd.body) < 24 {
+			return nil, fmt.Errorf("%w: short tkhd", ErrInvalidMP4)
+		}
+		t.id = binary.BigEndian.Uint32(tkhd.body[20:24])
+	} else {
+		t.id = binary.BigEndian.Uint32(tkhd.body[12:16])
+	}
+	mdhd := trak.find("mdia", "mdhd")
+	if mdhd == nil {
+		return nil, fmt.Errorf("%w: missing mdhd", ErrInvalidMP4)
+	}
+	var err error
+	if t.timescale, _, err = parseMvhd(mdhd.body); err != nil || t.timescale == 0 {
+		return nil, fmt.Errorf("%w: bad mdhd", ErrInvalidMP4)
+	}
+	stbl := trak.find("mdia", "minf", "stbl")
+	if stbl == nil {
+		return nil, fmt.Errorf("%w: missing stbl", ErrInvalidMP4)
+	}
+	var info trackInfo
+	if stsd := stbl.child("stsd"); stsd == nil {
+		return nil, fmt.Errorf("%w: missing stsd", ErrInvalidMP4)
+	} else if err := parseStsd(stsd.body, &info); err != nil {
+		return nil, err
+	}
+	t.codec = info.codec
+	if t.samples, err = expandSamples(stbl, fileSize); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// maxHLSSamples 单轨样本数上限，60fps 下约 19 小时，超过视为异常文件
+const maxHLSSamples = 1 << 22
+
+func expandSamples(stbl *mp4Box, fileSize int64) ([]hlsSample, error) {
+	bad := func(name string) error { return fmt.Errorf("%w: bad %s", ErrInvalidMP4, name) }
+	u32 := binary.BigEndian.Uint32
+
+	stsz := stbl.child("stsz")
+	if stsz == nil || len(stsz.body) < 12 {
+		return nil, bad("stsz")
+	}
+	uniform := u32(stsz.body[4:8])
+	count := int(u32(stsz.body[8:12]))
+	if uniform == 0 && len(stsz.body) < 12+4*count {
+		return nil, bad("stsz")
+	}
+
+	stts := stbl.child("stts")
+	if stts == nil || len(stts.body) < 8 {
+		return nil, bad("stts")
+	}
+	nStts := int(u32(stts.body[4:8]))
+	if len(stts.body) < 8+8*nStts {
+		return nil, bad("stts")
+	}
+
+	var chunkOffsets []int64
+	if stco := stbl.child("stco"); stco != nil {
+		if len(stco.body) < 8 {
+			return nil, bad("stco")
+		}
+		n := int(u32(stco.body[4:8]))
+		if len(stco.body) < 8+4*n {
+			return nil, bad("stco")
+		}
+		for e := 0; e < n; e++ {
+			chunkOffsets = append(chunkOffsets, int64(u32(stco.body[8+4*e:])))
+		}
+	} else if co64 := stbl.child("co64"); co64 != nil {
+		if len(co64.body) < 8 {
+			return nil, bad("co64")
+		}
+		n := int(u32(co64.body[4:8]))
+		if len(co64.body) < 8+8*n {
+			return nil, bad("co64")
+		}
+		for e := 0; e < n; e++ {
+			chunkOffsets = append(chunkOffsets, int64(binary.BigEndian.Uint64(co64.body[8+8*e:])))
+		}
+	} else {
+		return nil, bad("stco")
+	}
+	stsc := stbl.child("stsc")
+	if stsc == nil || len(stsc.body) < 8 {
+		return nil, bad("stsc")
+	}
+	nStsc := int(u32(stsc.body[4:8]))
+	if len(stsc.body) < 8+12*nStsc {
+		return nil, bad("stsc")
+	}
+
+	// 分配前先用 stts/stsc 的样本总数和文件大小校验 stsz 的 sample_count，
+	// 避免 uniform size 下伪造的计数触发超大分配
+	var sttsTotal, stscTotal uint64
+	for e := 0; e < nStts; e++ {
+		sttsTotal += uint64(u32(stts.body[8+8*e:]))
+	}
+	for e := 0; e < nStsc; e++ {
+		first := uint64(u32(stsc.body[8+12*e:]))
+		last := uint64(len(chunkOffsets))
+		if e+1 < nStsc {
+			last = uint64(u32(stsc.body[8+12*(e+1):])) - 1
+		}
+		if first < 1 || last > uint64(len(chunkOffsets)) {
+			return nil, bad("stsc")
+		}
+		if last >= first {
+			stscTotal += (last - first + 1) * uint64(u32(stsc.body[12+12*e:]))
+		}
+	}
+	if count > maxHLSSamples || uint64(count) != sttsTotal || uint64(count) > stscTotal {
+		return nil, bad("stsz")
+	}
+	if uniform != 0 && uint64(count)*uint64(uniform) > uint64(fileSize) {
+		return nil, fmt.Errorf("%w: sample out of range", ErrInvalidMP4)
+	}
+
+	samples := make([]hlsSample, count)
+	for i := range samples {
+		if uniform != 0 {
+			samples[i].size = uniform
+		} else {
+			samples[i].size = u32(stsz.body[12+4*i:])
+		}
+		samples[i].sync = true
+	}
+
+	// stts: 时间戳
+	var dts uint64
+	idx := 0
+	for e := 0; e < nStts; e++ {
+		c, delta := int(u32(stts.body[8+8*e:])), u32(stts.body[12+8*e:])
+		for j := 0; j < c && idx < count; j++ {
+			samples[idx].dts = dts
+			samples[idx].duration = delta
+			dts += uint64(delta)
+			idx++
+		}
+	}
+
+	// ctts: 显示时间偏移（有 B 帧时存在）
+	if ctts := stbl.child("ctts"); ctts != nil {
+		if len(ctts.body) < 8 {
+			return nil, bad("ctts")
+		}
+		n := int(u32(ctts.body[4:8]))
+		if len(ctts.body) < 8+8*n {
+			return nil, bad("ctts")
+		}
+		idx := 0
+		for e := 0; e < n; e++ {
+			c, off := int(u32(ctts.body[8+8*e:])), int32(u32(ctts.body[12+8*e:]))
+			for j := 0; j < c && idx < count; j++ {
+				samples[idx].cto = off
+				idx++
+			}
+		}
+	}
+
+	// stss: 关键帧，缺省表示全部是关键帧
+	if stss := stbl.child("stss"); stss != nil {
+		if len(stss.body) < 8 {
+			return nil, bad("stss")
+		}
+		n := int(u32(stss.body[4:8]))
+		if len(stss.body) < 8+4*n {
+			return nil, bad("stss")
+		}
+		for i := range samples {
+			samples[i].sync = false
+		}
+		for e := 0; e < n; e++ {
+			if s := int(u32(stss.body[8+4*e:])); s >= 1 && s <= count {
+				samples[s-1].sync = true
+			}
+		}
+	}
+
+	// stsc + stco/co64: 文件偏移
+	idx = 0
+	for e := 0; e < nStsc && idx < count; e++ {
+		first := int(u32(stsc.body[8+12*e:]))
+		perChunk := int(u32(stsc.body[12+12*e:]))
+		last := len(chunkOffsets)
+		if e+1 < nStsc {
+			last = int(u32(stsc.body[8+12*(e+1):])) - 1
+		}
+		for chunk := first; chunk <= last && idx < count; chunk++ {
+			off := chunkOffsets[chunk-1]
+			for j := 0; j < perChunk && idx < count; j++ {
+				samples[idx].offset = off
+				off += int64(samples[idx].size)
+				if off < 0 || off > fileSize {
+					return nil, fmt.Errorf("%w: sample out of range", ErrInvalidMP4)
+				}
+				idx++
+			}
+		}
+	}
+	if idx != count {
+		return nil, bad("stsc")
+	}
+	return samples, nil
+}
+
+// buildInitSegment ftyp + 去掉样本表的 moov + mvex
+func buildInitSegment(mvhd *mp4Box, tracks []*hlsTrack) []byte {
+	var ftyp bytes.Buffer
+	ftyp.WriteString("iso6")
+	ftyp.Write([]byte{0, 0, 0, 0})
+	ftyp.WriteString("iso6mp41isomavc1")
+
+	moov := &mp4Box{typ: "moov", children: []*mp4Box{mvhd}}
+	mvex := &mp4Box{typ: "mvex", children: []*mp4Box{}}
+	empty := append(fullBoxHeader(0, 0), 0, 0, 0, 0)
+	for _, t := range tracks {
+		stbl := t.trak.find("mdia", "minf", "stbl")
+		stbl.children = []*mp4Box{
+			stbl.child("stsd"),
+			{typ: "stts", body: empty},
+			{typ: "stsc", body: empty},
+			{typ: "stsz", body: append(fullBoxHeader(0, 0), 0, 0, 0, 0, 0, 0, 0, 0)},
+			{typ: "stco", body: empty},
+		}
+		moov.children = append(moov.children, t.trak)
+
+		trex := fullBoxHeader(0, 0)
+		trex = binary.BigEndian.AppendUint32(trex, t.id)
+		trex = binary.BigEndian.AppendUint32(trex, 1) // default_sample_description_index
+		trex = append(trex, make([]byte, 12)...)
+		mvex.children = append(mvex.children, &mp4Box{typ: "trex", body: trex})
+	}
+	moov.children = append(moov.children, mvex)
+
+	out := makeBox("ftyp", ftyp.Bytes())
+	return append(out, moov.encode()...)
+}
+
+const (
+	sampleFlagsSync    = 0x02000000 // sample_depends_on = 2
+	sampleFlagsNonSync = 0x01010000 // sample_depends_on = 1, is_non_sync_sample
+	trunFlags          = 0x000001 | 0x000100 | 0x000200 | 0x000400 | 0x000800
+)
+
+// buildMediaSegment moof（每个轨道一个 traf）+ mdat
+func buildMediaSegment(r io.ReaderAt, seq uint32, tracks []*hlsTrack, parts [][]hlsSample) ([]byte, error) {
+	moof := func(dataOffsets []int32) []byte {
+		mfhd := binary.BigEndian.AppendUint32(fullBoxHeader(0, 0), seq)
+		body := makeBox("mfhd", mfhd)
+		for i, t := range tracks {
+			samples := parts[i]
+			if len(samples) == 0 {
+				continue
+			}
+			tfhd := binary.BigEndian.AppendUint32(fullBoxHeader(0, 0x020000), t.id)
+			tfdt := binary.BigEndian.AppendUint64(fullBoxHeader(1, 0), samples[0].dts)
+			trun := fullBoxHeader(1, trunFlags)
+			trun = binary.BigEndian.AppendUint32(trun, uint32(len(samples)))
+			trun = binary.BigEndian.AppendUint32(trun, uint32(dataOffsets[i]))
+			for _, s := range samples {
+				flags := uint32(sampleFlagsNonSync)
+				if s.sync {
+					flags = sampleFlagsSync
+				}
+				trun = binary.BigEndian.AppendUint32(trun, s.duration)
+				trun = binary.BigEndian.AppendUint32(trun, s.size)
+				trun = binary.BigEndian.AppendUint32(trun, flags)
+				trun = binary.BigEndian.AppendUint32(trun, uint32(s.cto))
+			}
+			traf := makeBox("tfhd", tfhd)
+			traf = append(traf, makeBox("tfdt", tfdt)...)
+			traf = append(traf, makeBox("trun", trun)...)
+			body = append(body, makeBox("traf", traf)...)
+		}
+		return makeBox("moof", body)
+	}
+
+	// moof 长度与 data_offset 取值无关，先算长度再回填
+	offsets := make([]int32, len(tracks))
+	moofSize := len(moof(offsets))
+	var mdat bytes.Buffer
+	for i, samples := range parts {
+		offsets[i] = int32(moofSize + 8 + mdat.Len())
+		for _, s := range samples {
+			buf := make([]byte, s.size)
+			if _, err := r.ReadAt(buf, s.offset); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidMP4, err)
+			}
+			mdat.Write(buf)
+		}
+	}
+	out := moof(offsets)
+	return append(out, makeBox("mdat", mdat.Bytes())...), nil
+}
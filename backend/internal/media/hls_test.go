@@ -0,0 +1,399 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type hlsOutput struct {
+	names []string
+	files map[string][]byte
+}
+
+func packageTestHLS(t *testing.T, data []byte, segment time.Duration) (*hlsOutput, error) {
+	t.Helper()
+	out := &hlsOutput{files: make(map[string][]byte)}
+	err := PackageHLS(bytes.NewReader(data), int64(len(data)), segment, func(name string, b []byte) error {
+		out.names = append(out.names, name)
+		out.files[name] = b
+		return nil
+	})
+	return out, err
+}
+
+// topBoxes 按顺序列出顶层 box
+func topBoxes(t *testing.T, data []byte) ([]string, map[string][]byte) {
+	t.Helper()
+	var types []string
+	bodies := make(map[string][]byte)
+	if err := eachBox(data, func(typ string, body []byte) error {
+		types = append(types, typ)
+		bodies[typ] = body
+		return nil
+	}); err != nil {
+		t.Fatalf("parse boxes: %v", err)
+	}
+	return types, bodies
+}
+
+type testTraf struct {
+	trackID    uint32
+	baseTime   uint64
+	count      int
+	dataOffset int
+	sizes      []uint32
+	flags      []uint32
+}
+
+func parseMoof(t *testing.T, moof []byte) []testTraf {
+	t.Helper()
+	var trafs []testTraf
+	err := eachBox(moof, func(typ string, body []byte) error {
+		if typ != "traf" {
+			return nil
+		}
+		var tr testTraf
+		err := eachBox(body, func(typ string, b []byte) error {
+			switch typ {
+			case "tfhd":
+				tr.trackID = binary.BigEndian.Uint32(b[4:8])
+			case "tfdt":
+				tr.baseTime = binary.BigEndian.Uint64(b[4:12])
+			case "trun":
+				tr.count = int(binary.BigEndian.Uint32(b[4:8]))
+				tr.dataOffset = int(int32(binary.BigEndian.Uint32(b[8:12])))
+				for i := 0; i < tr.count; i++ {
+					entry := b[12+16*i:]
+					tr.sizes = append(tr.sizes, binary.BigEndian.Uint32(entry[4:8]))
+					tr.flags = append(tr.flags, binary.BigEndian.Uint32(entry[8:12]))
+				}
+			}
+			return nil
+		})
+		trafs = append(trafs, tr)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("parse moof: %v", err)
+	}
+	return trafs
+}
+
+func TestPackageHLS(t *testing.T) {
+	video, audio := testVideoTrack(), testAudioTrack()
+	out, err := packageTestHLS(t, buildTestMP4([]testTrack{video, audio}, nil), 2*time.Second)
+	if err != nil {
+		t.Fatalf("package: %v", err)
+	}
+	wantNames := []string{HLSInitName, "seg_00000.m4s", "seg_00001.m4s", HLSPlaylistName}
+	if strings.Join(out.names, ",") != strings.Join(wantNames, ",") {
+		t.Fatalf("emitted %v, want %v", out.names, wantNames)
+	}
+
+	playlist := string(out.files[HLSPlaylistName])
+	for _, want := range []string{
+		"#EXT-X-TARGETDURATION:3\n",
+		`#EXT-X-MAP:URI="init.mp4"`,
+		"#EXTINF:3.000,\nseg_00000.m4s\n",
+		"#EXTINF:2.000,\nseg_00001.m4s\n",
+		"#EXT-X-ENDLIST",
+	} {
+		if !strings.Contains(playlist, want) {
+			t.Errorf("playlist missing %q:\n%s", want, playlist)
+		}
+	}
+
+	// init 段：ftyp + moov，样本表清空，mvex 中每个轨道一个 trex
+	types, bodies := topBoxes(t, out.files[HLSInitName])
+	if strings.Join(types, ",") != "ftyp,moov" {
+		t.Fatalf("init boxes = %v", types)
+	}
+	moov, err := parseBoxTree(bodies["moov"])
+	if err != nil {
+		t.Fatalf("parse init moov: %v", err)
+	}
+	root := &mp4Box{typ: "moov", children: moov}
+	var trex int
+	if err := eachBox(root.child("mvex").encode()[8:], func(typ string, _ []byte) error {
+		if typ == "trex" {
+			trex++
+		}
+		return nil
+	}); err != nil || trex != 2 {
+		t.Fatalf("trex count = %d, err = %v", trex, err)
+	}
+	stsz := trakOf(root, "vide").find("mdia", "minf", "stbl", "stsz")
+	if stsz == nil || binary.BigEndian.Uint32(stsz.body[8:12]) != 0 {
+		t.Fatalf("init stsz should be empty")
+	}
+
+	// 分片按视频关键帧切分：第一个分片是视频样本 0-2 和时间早于 3s 的音频，第二个是剩余样本
+	segments := []struct {
+		name        string
+		videoFrom   int
+		videoTo     int
+		audioFrom   int
+		audioTo     int
+		videoBase   uint64
+		audioBase   uint64
+		nonSyncTail bool
+	}{
+		{"seg_00000.m4s", 0, 3, 0, 6, 0, 0, true},
+		{"seg_00001.m4s", 3, 5, 6, 10, 3000, 3000, true},
+	}
+	for _, seg := range segments {
+		data := out.files[seg.name]
+		types, bodies := topBoxes(t, data)
+		if strings.Join(types, ",") != "moof,mdat" {
+			t.Fatalf("%s boxes = %v", seg.name, types)
+		}
+		trafs := parseMoof(t, bodies["moof"])
+		if len(trafs) != 2 {
+			t.Fatalf("%s traf count = %d", seg.name, len(trafs))
+		}
+		for i, want := range []struct {
+			track    testTrack
+			from, to int
+			base     uint64
+		}{
+			{video, seg.videoFrom, seg.videoTo, seg.videoBase},
+			{audio, seg.audioFrom, seg.audioTo, seg.audioBase},
+		} {
+			tr := trafs[i]
+			if tr.trackID != want.track.id || tr.count != want.to-want.from || tr.baseTime != want.base {
+				t.Fatalf("%s traf %d = %+v", seg.name, i, tr)
+			}
+			// data_offset 相对 moof 起点，指向该轨道在 mdat 中的第一个样本
+			pos := tr.dataOffset
+			for j := want.from; j < want.to; j++ {
+				size := int(want.track.sizes[j])
+				if tr.sizes[j-want.from] != uint32(size) {
+					t.Fatalf("%s track %d sample %d size = %d", seg.name, want.track.id, j, tr.sizes[j-want.from])
+				}
+				if !bytes.Equal(data[pos:pos+size], bytes.Repeat([]byte{sampleByte(want.track.id, j)}, size)) {
+					t.Fatalf("%s track %d sample %d has wrong payload", seg.name, want.track.id, j)
+				}
+				pos += size
+			}
+		}
+		if trafs[0].flags[0] != sampleFlagsSync || trafs[0].flags[1] != sampleFlagsNonSync {
+			t.Fatalf("%s video flags = %x", seg.name, trafs[0].flags)
+		}
+	}
+}
+
+func TestPackageHLSVideoOnly(t *testing.T) {
+	out, err := packageTestHLS(t, buildTestMP4([]testTrack{testVideoTrack()}, nil), time.Hour)
+	if err != nil {
+		t.Fatalf("package: %v", err)
+	}
+	if len(out.names) != 3 {
+		t.Fatalf("emitted %v, want init + 1 segment + playlist", out.names)
+	}
+	_, bodies := topBoxes(t, out.files["seg_00000.m4s"])
+	if trafs := parseMoof(t, bodies["moof"]); len(trafs) != 1 || trafs[0].count != 5 {
+		t.Fatalf("trafs = %+v", trafs)
+	}
+	if !strings.Contains(string(out.files[HLSPlaylistName]), "#EXTINF:5.000,") {
+		t.Fatalf("playlist = %s", out.files[HLSPlaylistName])
+	}
+}
+
+func TestPackageHLSInvalid(t *testing.T) {
+	av := func(mutate func(moov *mp4Box)) []byte {
+		return buildTestMP4([]testTrack{testVideoTrack(), testAudioTrack()}, mutate)
+	}
+	videoStbl := func(moov *mp4Box) *mp4Box {
+		return trakOf(moov, "vide").find("mdia", "minf", "stbl")
+	}
+	withCodec := func(handler, codec string) []byte {
+		return av(func(moov *mp4Box) {
+			stsd := trakOf(moov, handler).find("mdia", "minf", "stbl", "stsd")
+			copy(stsd.body[12:16], codec)
+		})
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"already fragmented", av(func(moov *mp4Box) { moov.children = append(moov.children, box("mvex")) }), ErrHLSUnsupported},
+		{"hevc video", withCodec("vide", "hvc1"), ErrHLSUnsupported},
+		{"opus audio", withCodec("soun", "Opus"), ErrHLSUnsupported},
+		{"audio only", buildTestMP4([]testTrack{testAudioTrack()}, nil), ErrInvalidMP4},
+		{"missing moov", makeBox("ftyp", []byte("isom\x00\x00\x02\x00")), ErrInvalidMP4},
+		{"missing mvhd", av(func(moov *mp4Box) { removeChild(moov, "mvhd") }), ErrInvalidMP4},
+		{"missing hdlr", av(func(moov *mp4Box) { removeChild(trakOf(moov, "vide").child("mdia"), "hdlr") }), ErrInvalidMP4},
+		{"short tkhd", av(func(moov *mp4Box) { trakOf(moov, "vide").child("tkhd").body = make([]byte, 16) }), ErrInvalidMP4},
+		{"zero timescale", av(func(moov *mp4Box) {
+			mdhd := trakOf(moov, "vide").find("mdia", "mdhd")
+			copy(mdhd.body[12:16], u32s(0))
+		}), ErrInvalidMP4},
+		{"missing stsd", av(func(moov *mp4Box) { removeChild(videoStbl(moov), "stsd") }), ErrInvalidMP4},
+		{"missing stsz", av(func(moov *mp4Box) { removeChild(videoStbl(moov), "stsz") }), ErrInvalidMP4},
+		{"truncated stsz table", av(func(moov *mp4Box) {
+			stsz := videoStbl(moov).child("stsz")
+			stsz.body = stsz.body[:len(stsz.body)-4]
+		}), ErrInvalidMP4},
+		// uniform size 下伪造的 sample_count 必须在分配前被拒绝
+		{"forged uniform count", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stsz").body = append(fullBoxHeader(0, 0), u32s(1, 0xffffffff)...)
+		}), ErrInvalidMP4},
+		{"forged count matching stts", av(func(moov *mp4Box) {
+			stbl := videoStbl(moov)
+			stbl.child("stsz").body = append(fullBoxHeader(0, 0), u32s(1, 0xffffffff)...)
+			stbl.child("stts").body = append(fullBoxHeader(0, 0), u32s(1, 0xffffffff, 1)...)
+			stbl.child("stsc").body = append(fullBoxHeader(0, 0), u32s(1, 1, 0xffffffff, 1)...)
+		}), ErrInvalidMP4},
+		{"uniform samples exceed file", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stsz").body = append(fullBoxHeader(0, 0), u32s(1<<20, 5)...)
+		}), ErrInvalidMP4},
+		{"stts shorter than stsz", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stts").body = append(fullBoxHeader(0, 0), u32s(1, 4, 1000)...)
+		}), ErrInvalidMP4},
+		{"stts longer than stsz", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stts").body = append(fullBoxHeader(0, 0), u32s(1, 6, 1000)...)
+		}), ErrInvalidMP4},
+		{"truncated stts", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stts").body = append(fullBoxHeader(0, 0), u32s(3, 5, 1000)...)
+		}), ErrInvalidMP4},
+		{"truncated ctts", av(func(moov *mp4Box) {
+			stbl := videoStbl(moov)
+			stbl.children = append(stbl.children, leaf("ctts", append(fullBoxHeader(0, 0), u32s(2, 1, 0)...)))
+		}), ErrInvalidMP4},
+		{"truncated stss", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stss").body = append(fullBoxHeader(0, 0), u32s(4, 1)...)
+		}), ErrInvalidMP4},
+		{"missing stco", av(func(moov *mp4Box) { removeChild(videoStbl(moov), "stco") }), ErrInvalidMP4},
+		{"truncated co64", av(func(moov *mp4Box) {
+			stbl := videoStbl(moov)
+			removeChild(stbl, "stco")
+			stbl.children = append(stbl.children, leaf("co64", append(fullBoxHeader(0, 0), u32s(1, 0)...)))
+		}), ErrInvalidMP4},
+		{"missing stsc", av(func(moov *mp4Box) { removeChild(videoStbl(moov), "stsc") }), ErrInvalidMP4},
+		{"stsc chunk out of range", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stsc").body = append(fullBoxHeader(0, 0), u32s(1, 2, 5, 1)...)
+		}), ErrInvalidMP4},
+		{"stsc covers fewer samples", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stsc").body = append(fullBoxHeader(0, 0), u32s(1, 1, 4, 1)...)
+		}), ErrInvalidMP4},
+		{"sample beyond file", av(func(moov *mp4Box) {
+			videoStbl(moov).child("stco").body = append(fullBoxHeader(0, 0), u32s(1, 0x7ffffff0)...)
+		}), ErrInvalidMP4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := packageTestHLS(t, tc.data, 2*time.Second)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+			if len(out.names) != 0 {
+				t.Fatalf("emitted %v before failing", out.names)
+			}
+		})
+	}
+}
+
+func TestPackageHLSEmitError(t *testing.T) {
+	data := buildTestMP4([]testTrack{testVideoTrack()}, nil)
+	stop := errors.New("storage unavailable")
+	calls := 0
+	err := PackageHLS(bytes.NewReader(data), int64(len(data)), 2*time.Second, func(string, []byte) error {
+		calls++
+		if calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Fatalf("err = %v after %d calls, want emit error after 2", err, calls)
+	}
+}
+
+func TestExpandSamples(t *testing.T) {
+	track := testVideoTrack()
+	track.ctts = []int32{2000, 0, -1000, 1000, 0}
+	stbl := track.trakBox(100).find("mdia", "minf", "stbl")
+	samples, err := expandSamples(stbl, 1000)
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	want := []hlsSample{
+		{offset: 100, size: 10, dts: 0, duration: 1000, cto: 2000, sync: true},
+		{offset: 110, size: 5, dts: 1000, duration: 1000, cto: 0},
+		{offset: 115, size: 5, dts: 2000, duration: 1000, cto: -1000},
+		{offset: 120, size: 10, dts: 3000, duration: 1000, cto: 1000, sync: true},
+		{offset: 130, size: 5, dts: 4000, duration: 1000, cto: 0},
+	}
+	if len(samples) != len(want) {
+		t.Fatalf("samples = %+v", samples)
+	}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("sample %d = %+v, want %+v", i, samples[i], want[i])
+		}
+	}
+}
+
+func TestExpandSamplesMultipleChunks(t *testing.T) {
+	// 两个 chunk，每个 2 个样本，统一大小 4
+	stbl := box("stbl",
+		leaf("stsz", append(fullBoxHeader(0, 0), u32s(4, 4)...)),
+		leaf("stts", append(fullBoxHeader(0, 0), u32s(1, 4, 10)...)),
+		leaf("stsc", append(fullBoxHeader(0, 0), u32s(1, 1, 2, 1)...)),
+		leaf("stco", append(fullBoxHeader(0, 0), u32s(2, 0, 100)...)),
+	)
+	samples, err := expandSamples(stbl, 200)
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	var offsets []int64
+	for _, s := range samples {
+		offsets = append(offsets, s.offset)
+		if !s.sync || s.size != 4 {
+			t.Fatalf("unexpected sample %+v", s)
+		}
+	}
+	if want := []int64{0, 4, 100, 104}; len(offsets) != 4 || offsets[0] != want[0] || offsets[1] != want[1] || offsets[2] != want[2] || offsets[3] != want[3] {
+		t.Fatalf("offsets = %v, want %v", offsets, want)
+	}
+}
+
+func TestHLSKeys(t *testing.T) {
+	key := HLSKey("videos/1/20250101/a.mp4", HLSPlaylistName)
+	if key != "hls/videos/1/20250101/a.mp4/index.m3u8" {
+		t.Fatalf("HLSKey = %s", key)
+	}
+	cases := []struct {
+		key    string
+		source string
+		ok     bool
+	}{
+		{key, "videos/1/20250101/a.mp4", true},
+		{"hls/videos/1/a.mp4/seg_00001.m4s", "videos/1/a.mp4", true},
+		{"hls/index.m3u8", "", false},
+		{"videos/1/a.mp4", "", false},
+	}
+	for _, tc := range cases {
+		if source, ok := HLSSource(tc.key); source != tc.source || ok != tc.ok {
+			t.Errorf("HLSSource(%q) = %q, %v", tc.key, source, ok)
+		}
+	}
+	if !IsPlaylist(key) || IsPlaylist("hls/a.mp4/init.mp4") {
+		t.Fatalf("IsPlaylist mismatch")
+	}
+}
+
+func TestRewritePlaylist(t *testing.T) {
+	in := "#EXTM3U\r\n#EXT-X-MAP:URI=\"init.mp4\"\r\n#EXTINF:6.000,\r\nseg_00000.m4s\r\n\r\n#EXT-X-ENDLIST\r\n"
+	got := string(RewritePlaylist([]byte(in), func(uri string) string { return "/media/" + uri + "?sig=x" }))
+	want := "#EXTM3U\n#EXT-X-MAP:URI=\"/media/init.mp4?sig=x\"\n#EXTINF:6.000,\n/media/seg_00000.m4s?sig=x\n\n#EXT-X-ENDLIST"
+	if got != want {
+		t.Fatalf("RewritePlaylist =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+)
+
+var ErrInvalidImage = errors.New("invalid image file")
+
+type ImageMeta struct {
+	// jpeg | png | webp
+	Format string
+	Width  int
+	Height int
+}
+
+func (m *ImageMeta) ContentType() string {
+	return "image/" + m.Format
+}
+
+// MatchExt 文件扩展名是否与识别出的格式一致
+func (m *ImageMeta) MatchExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg":
+		return m.Format == "jpeg"
+	case ".png":
+		return m.Format == "png"
+	case ".webp":
+		return m.Format == "webp"
+	default:
+		return false
+	}
+}
+
+// ProbeImage 根据文件头识别 jpeg/png/webp 并读取宽高，不解码像素
+func ProbeImage(r io.Reader) (*ImageMeta, error) {
+	head := make([]byte, 32)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	head = head[:n]
+	if len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP" {
+		return probeWebP(head)
+	}
+	cfg, format, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(head), r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, fmt.Errorf("%w: unsupported format %s", ErrInvalidImage, format)
+	}
+	return &ImageMeta{Format: format, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// WebP 只解析首个 chunk 的头部：VP8 / VP8L / VP8X
+func probeWebP(b []byte) (*ImageMeta, error) {
+	if len(b) < 30 {
+		return nil, fmt.Errorf("%w: short webp", ErrInvalidImage)
+	}
+	meta := &ImageMeta{Format: "webp"}
+	chunk := b[20:]
+	switch string(b[12:16]) {
+	case "VP8 ":
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return nil, fmt.Errorf("%w: bad vp8 start code", ErrInvalidImage)
+		}
+		meta.Width = int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff)
+		meta.Height = int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff)
+	case "VP8L":
+		if chunk[0] != 0x2f {
+			return nil, fmt.Errorf("%w: bad vp8l signature", ErrInvalidImage)
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		meta.Width = int(bits&0x3fff) + 1
+		meta.Height = int((bits>>14)&0x3fff) + 1
+	case "VP8X":
+		meta.Width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		meta.Height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+	default:
+		return nil, fmt.Errorf("%w: unknown webp chunk", ErrInvalidImage)
+	}
+	if meta.Width <= 0 || meta.Height <= 0 {
+		return nil, fmt.Errorf("%w: zero size", ErrInvalidImage)
+	}
+	return meta, nil
+}
@@ -0,0 +1,134 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodedImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// webpHeader RIFF 头 + 首个 chunk，chunk 内容补零到 10 字节以上
+func webpHeader(chunk string, payload ...byte) []byte {
+	b := []byte("RIFF\x00\x00\x00\x00WEBP" + chunk)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(payload)))
+	b = append(b, payload...)
+	for len(b) < 32 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func vp8Payload(w, h uint16) []byte {
+	p := []byte{0, 0, 0, 0x9d, 0x01, 0x2a}
+	p = binary.LittleEndian.AppendUint16(p, w)
+	return binary.LittleEndian.AppendUint16(p, h)
+}
+
+func vp8lPayload(w, h uint32) []byte {
+	return binary.LittleEndian.AppendUint32([]byte{0x2f}, (w-1)|(h-1)<<14)
+}
+
+func vp8xPayload(w, h uint32) []byte {
+	p := make([]byte, 4)
+	p = append(p, byte(w-1), byte((w-1)>>8), byte((w-1)>>16))
+	return append(p, byte(h-1), byte((h-1)>>8), byte((h-1)>>16))
+}
+
+func TestProbeImage(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want ImageMeta
+	}{
+		{"png", encodedImage(t, "png", 3, 2), ImageMeta{Format: "png", Width: 3, Height: 2}},
+		{"jpeg", encodedImage(t, "jpeg", 16, 9), ImageMeta{Format: "jpeg", Width: 16, Height: 9}},
+		{"webp vp8", webpHeader("VP8 ", vp8Payload(320, 240)...), ImageMeta{Format: "webp", Width: 320, Height: 240}},
+		// VP8 宽高高 2 位是缩放系数，需要屏蔽
+		{"webp vp8 scaled", webpHeader("VP8 ", vp8Payload(0xc000|320, 0x4000|240)...), ImageMeta{Format: "webp", Width: 320, Height: 240}},
+		{"webp vp8l", webpHeader("VP8L", vp8lPayload(1000, 16384)...), ImageMeta{Format: "webp", Width: 1000, Height: 16384}},
+		{"webp vp8x", webpHeader("VP8X", vp8xPayload(70000, 3)...), ImageMeta{Format: "webp", Width: 70000, Height: 3}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta, err := ProbeImage(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("probe: %v", err)
+			}
+			if *meta != tc.want {
+				t.Fatalf("meta = %+v, want %+v", *meta, tc.want)
+			}
+		})
+	}
+}
+
+func TestProbeImageInvalid(t *testing.T) {
+	pngData := encodedImage(t, "png", 3, 2)
+	jpegData := encodedImage(t, "jpeg", 16, 9)
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"garbage", []byte("definitely not an image file at all")},
+		{"truncated png", pngData[:20]},
+		{"png without ihdr", pngData[:8]},
+		{"truncated jpeg", jpegData[:4]},
+		{"unsupported gif", gif},
+		{"short webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")},
+		{"bad vp8 start code", webpHeader("VP8 ", 0, 0, 0, 0x9d, 0x01, 0x2b, 1, 0, 1, 0)},
+		{"zero size vp8", webpHeader("VP8 ", vp8Payload(0, 240)...)},
+		{"bad vp8l signature", webpHeader("VP8L", 0x2e, 0, 0, 0, 0)},
+		{"unknown webp chunk", webpHeader("ALPH", 0, 0, 0, 0)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if meta, err := ProbeImage(bytes.NewReader(tc.data)); !errors.Is(err, ErrInvalidImage) {
+				t.Fatalf("meta = %+v, err = %v, want ErrInvalidImage", meta, err)
+			}
+		})
+	}
+}
+
+func TestImageMetaMatchExt(t *testing.T) {
+	cases := []struct {
+		format, ext string
+		want        bool
+	}{
+		{"jpeg", ".jpg", true},
+		{"jpeg", ".JPEG", true},
+		{"png", ".png", true},
+		{"webp", ".webp", true},
+		{"png", ".jpg", false},
+		{"jpeg", ".png", false},
+		{"webp", ".gif", false},
+		{"png", "", false},
+	}
+	for _, tc := range cases {
+		m := ImageMeta{Format: tc.format}
+		if got := m.MatchExt(tc.ext); got != tc.want {
+			t.Errorf("%s MatchExt(%q) = %v, want %v", tc.format, tc.ext, got, tc.want)
+		}
+	}
+}
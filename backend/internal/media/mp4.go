@@ -0,0 +1,329 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidMP4 = errors.New("invalid mp4 file")
+
+// moov 一般只有几百 KB，超过该大小视为异常文件
+const maxMoovSize = 64 << 20
+
+type VideoMeta struct {
+	DurationMs int64
+	Width      int
+	Height     int
+	VideoCodec string
+	AudioCodec string
+	// ftyp 中的 major brand，例如 isom/mp42
+	Brand string
+}
+
+type boxHeader struct {
+	typ        string
+	size       int64 // 含头部的总长度
+	headerSize int64
+}
+
+// ProbeMP4 解析 MP4 顶层 box，校验 ftyp/moov 并从 moov 中提取时长、分辨率和编码
+func ProbeMP4(r io.ReadSeeker, fileSize int64) (*VideoMeta, error) {
+	var (
+		meta    VideoMeta
+		hasFtyp bool
+		moov    []byte
+		offset  int64
+	)
+	for offset < fileSize {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		h, err := readBoxHeader(r, fileSize-offset)
+		if err != nil {
+			return nil, err
+		}
+		switch h.typ {
+		case "ftyp":
+			if hasFtyp || moov != nil {
+				return nil, fmt.Errorf("%w: unexpected ftyp", ErrInvalidMP4)
+			}
+			body, err := readBody(r, h, 1024)
+			if err != nil {
+				return nil, err
+			}
+			if len(body) < 8 {
+				return nil, fmt.Errorf("%w: short ftyp", ErrInvalidMP4)
+			}
+			meta.Brand = string(body[:4])
+			hasFtyp = true
+		case "moov":
+			if !hasFtyp {
+				return nil, fmt.Errorf("%w: moov before ftyp", ErrInvalidMP4)
+			}
+			if moov != nil {
+				return nil, fmt.Errorf("%w: duplicate moov", ErrInvalidMP4)
+			}
+			if moov, err = readBody(r, h, maxMoovSize); err != nil {
+				return nil, err
+			}
+		default:
+			if !hasFtyp && h.typ != "free" && h.typ != "skip" {
+				return nil, fmt.Errorf("%w: missing ftyp", ErrInvalidMP4)
+			}
+		}
+		offset += h.size
+	}
+	if !hasFtyp {
+		return nil, fmt.Errorf("%w: missing ftyp", ErrInvalidMP4)
+	}
+	if moov == nil {
+		return nil, fmt.Errorf("%w: missing moov", ErrInvalidMP4)
+	}
+	if err := parseMoov(moov, &meta); err != nil {
+		return nil, err
+	}
+	if meta.VideoCodec == "" || meta.Width <= 0 || meta.Height <= 0 {
+		return nil, fmt.Errorf("%w: no video track", ErrInvalidMP4)
+	}
+	return &meta, nil
+}
+
+func readBoxHeader(r io.Reader, remaining int64) (boxHeader, error) {
+	var buf [16]byte
+	if remaining < 8 {
+		return boxHeader{}, fmt.Errorf("%w: truncated box", ErrInvalidMP4)
+	}
+	if _, err := io.ReadFull(r, buf[:8]); err != nil {
+		return boxHeader{}, fmt.Errorf("%w: %v", ErrInvalidMP4, err)
+	}
+	h := boxHeader{
+		typ:        string(buf[4:8]),
+		size:       int64(binary.BigEndian.Uint32(buf[:4])),
+		headerSize: 8,
+	}
+	switch h.size {
+	case 0:
+		h.size = remaining
+	case 1:
+		if remaining < 16 {
+			return boxHeader{}, fmt.Errorf("%w: truncated box", ErrInvalidMP4)
+		}
+		if _, err := io.ReadFull(r, buf[8:16]); err != nil {
+			return boxHeader{}, fmt.Errorf("%w: %v", ErrInvalidMP4, err)
+		}
+		h.size = int64(binary.BigEndian.Uint64(buf[8:16]))
+		h.headerSize = 16
+	}
+	if !validBoxType(h.typ) || h.size < h.headerSize || h.size > remaining {
+		return boxHeader{}, fmt.Errorf("%w: bad box %q", ErrInvalidMP4, h.typ)
+	}
+	return h, nil
+}
+
+func readBody(r io.Reader, h boxHeader, limit int64) ([]byte, error) {
+	n := h.size - h.headerSize
+	if n > limit {
+		return nil, fmt.Errorf("%w: %s box too large", ErrInvalidMP4, h.typ)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMP4, err)
+	}
+	return body, nil
+}
+
+// box 类型必须是 4 个可打印字符
+func validBoxType(t string) bool {
+	for i := 0; i < len(t); i++ {
+		if t[i] < 0x20 || t[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// 遍历内存中的子 box
+func eachBox(data []byte, fn func(typ string, body []byte) error) error {
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		h, err := readBoxHeader(r, int64(r.Len()))
+		if err != nil {
+			return err
+		}
+		body := make([]byte, h.size-h.headerSize)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidMP4, err)
+		}
+		if err := fn(h.typ, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseMoov(moov []byte, meta *VideoMeta) error {
+	var (
+		hasMvhd   bool
+		hasMvex   bool
+		timescale uint32
+		duration  uint64
+	)
+	err := eachBox(moov, func(typ string, body []byte) error {
+		switch typ {
+		case "mvhd":
+			var err error
+			if timescale, duration, err = parseMvhd(body); err != nil {
+				return err
+			}
+			hasMvhd = true
+		case "mvex":
+			hasMvex = true
+		case "trak":
+			return parseTrak(body, meta)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !hasMvhd || timescale == 0 {
+		return fmt.Errorf("%w: missing mvhd", ErrInvalidMP4)
+	}
+	// 分片 MP4 的 mvhd 时长可以为 0
+	if duration == 0 && !hasMvex {
+		return fmt.Errorf("%w: zero duration", ErrInvalidMP4)
+	}
+	meta.DurationMs = int64(duration * 1000 / uint64(timescale))
+	return nil
+}
+
+func parseMvhd(b []byte) (timescale uint32, duration uint64, err error) {
+	if len(b) < 4 {
+		return 0, 0, fmt.Errorf("%w: short mvhd", ErrInvalidMP4)
+	}
+	switch b[0] {
+	case 0:
+		if len(b) < 20 {
+			return 0, 0, fmt.Errorf("%w: short mvhd", ErrInvalidMP4)
+		}
+		return binary.BigEndian.Uint32(b[12:16]), uint64(binary.BigEndian.Uint32(b[16:20])), nil
+	case 1:
+		if len(b) < 32 {
+			return 0, 0, fmt.Errorf("%w: short mvhd", ErrInvalidMP4)
+		}
+		return binary.BigEndian.Uint32(b[20:24]), binary.BigEndian.Uint64(b[24:32]), nil
+	default:
+		return 0, 0, fmt.Errorf("%w: unknown mvhd version", ErrInvalidMP4)
+	}
+}
+
+type trackInfo struct {
+	handler string
+	width   int
+	height  int
+	codec   string
+	entryW  int
+	entryH  int
+}
+
+func parseTrak(trak []byte, meta *VideoMeta) error {
+	var t trackInfo
+	err := eachBox(trak, func(typ string, body []byte) error {
+		switch typ {
+		case "tkhd":
+			w, h, err := parseTkhd(body)
+			if err != nil {
+				return err
+			}
+			t.width, t.height = w, h
+		case "mdia":
+			return parseMdia(body, &t)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	switch t.handler {
+	case "vide":
+		if meta.VideoCodec != "" {
+			return nil
+		}
+		meta.VideoCodec = t.codec
+		meta.Width, meta.Height = t.width, t.height
+		if meta.Width == 0 || meta.Height == 0 {
+			meta.Width, meta.Height = t.entryW, t.entryH
+		}
+	case "soun":
+		if meta.AudioCodec == "" {
+			meta.AudioCodec = t.codec
+		}
+	}
+	return nil
+}
+
+// tkhd 末尾是 16.16 定点数的宽高
+func parseTkhd(b []byte) (int, int, error) {
+	if len(b) < 4 {
+		return 0, 0, fmt.Errorf("%w: short tkhd", ErrInvalidMP4)
+	}
+	want := 84
+	if b[0] == 1 {
+		want = 96
+	}
+	if len(b) < want {
+		return 0, 0, fmt.Errorf("%w: short tkhd", ErrInvalidMP4)
+	}
+	w := binary.BigEndian.Uint32(b[want-8 : want-4])
+	h := binary.BigEndian.Uint32(b[want-4 : want])
+	return int(w >> 16), int(h >> 16), nil
+}
+
+func parseMdia(mdia []byte, t *trackInfo) error {
+	return eachBox(mdia, func(typ string, body []byte) error {
+		switch typ {
+		case "hdlr":
+			if len(body) < 12 {
+				return fmt.Errorf("%w: short hdlr", ErrInvalidMP4)
+			}
+			t.handler = string(body[8:12])
+		case "minf":
+			return eachBox(body, func(typ string, body []byte) error {
+				if typ != "stbl" {
+					return nil
+				}
+				return eachBox(body, func(typ string, body []byte) error {
+					if typ == "stsd" {
+						return parseStsd(body, t)
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+}
+
+// stsd 第一个 sample entry 的类型即编码，如 avc1/hvc1/mp4a
+func parseStsd(b []byte, t *trackInfo) error {
+	if len(b) < 8 || binary.BigEndian.Uint32(b[4:8]) == 0 {
+		return fmt.Errorf("%w: empty stsd", ErrInvalidMP4)
+	}
+	entries := b[8:]
+	r := bytes.NewReader(entries)
+	h, err := readBoxHeader(r, int64(len(entries)))
+	if err != nil {
+		return err
+	}
+	t.codec = h.typ
+	// visual sample entry: 6 reserved + 2 data_reference_index + 16 pre_defined/reserved + width + height
+	entry := entries[h.headerSize:h.size]
+	if len(entry) >= 28 {
+		t.entryW = int(binary.BigEndian.Uint16(entry[24:26]))
+		t.entryH = int(binary.BigEndian.Uint16(entry[26:28]))
+	}
+	return nil
+}
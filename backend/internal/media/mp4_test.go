@@ -0,0 +1,261 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// testTrack 构造测试用 MP4 的一条轨道，样本全部放在同一个 chunk 中
+type testTrack struct {
+	id        uint32
+	handler   string // vide | soun
+	codec     string // avc1 | mp4a | ...
+	timescale uint32
+	sizes     []uint32
+	delta     uint32
+	sync      []uint32 // 关键帧序号（从 1 开始），nil 表示不写 stss
+	ctts      []int32  // 每个样本的显示时间偏移，nil 表示不写 ctts
+	width     int
+	height    int
+}
+
+func testVideoTrack() testTrack {
+	return testTrack{
+		id: 1, handler: "vide", codec: "avc1", timescale: 1000,
+		sizes: []uint32{10, 5, 5, 10, 5}, delta: 1000, sync: []uint32{1, 4},
+		width: 640, height: 360,
+	}
+}
+
+func testAudioTrack() testTrack {
+	return testTrack{
+		id: 2, handler: "soun", codec: "mp4a", timescale: 1000,
+		sizes: []uint32{3, 3, 3, 3, 3, 3, 3, 3, 3, 3}, delta: 500,
+	}
+}
+
+// sampleByte 样本内容：轨道号在高 4 位、样本序号在低 4 位，便于校验分片里的 mdat
+func sampleByte(trackID uint32, i int) byte {
+	return byte(trackID<<4) | byte(i&0x0f)
+}
+
+func u32s(vs ...uint32) []byte {
+	var b []byte
+	for _, v := range vs {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func box(typ string, children ...*mp4Box) *mp4Box {
+	return &mp4Box{typ: typ, children: children}
+}
+
+func leaf(typ string, body []byte) *mp4Box {
+	return &mp4Box{typ: typ, body: body}
+}
+
+func mvhdBody(timescale, duration uint32) []byte {
+	b := append(fullBoxHeader(0, 0), u32s(0, 0, timescale, duration)...)
+	return append(b, make([]byte, 80)...)
+}
+
+func tkhdBody(id uint32, width, height int) []byte {
+	b := append(fullBoxHeader(0, 0), u32s(0, 0, id, 0, 0)...)
+	b = append(b, make([]byte, 84-len(b)-8)...)
+	return append(b, u32s(uint32(width)<<16, uint32(height)<<16)...)
+}
+
+func hdlrBody(handler string) []byte {
+	b := append(fullBoxHeader(0, 0), 0, 0, 0, 0)
+	b = append(b, handler...)
+	return append(b, make([]byte, 13)...)
+}
+
+func stsdBody(codec string, width, height int) []byte {
+	entry := make([]byte, 78)
+	binary.BigEndian.PutUint16(entry[6:8], 1)
+	binary.BigEndian.PutUint16(entry[24:26], uint16(width))
+	binary.BigEndian.PutUint16(entry[26:28], uint16(height))
+	return append(append(fullBoxHeader(0, 0), u32s(1)...), makeBox(codec, entry)...)
+}
+
+// trakBox 生成轨道，chunkOffset 为样本数据在文件中的起始位置
+func (t testTrack) trakBox(chunkOffset uint32) *mp4Box {
+	n := uint32(len(t.sizes))
+	stbl := box("stbl",
+		leaf("stsd", stsdBody(t.codec, t.width, t.height)),
+		leaf("stts", append(fullBoxHeader(0, 0), u32s(1, n, t.delta)...)),
+	)
+	if t.ctts != nil {
+		body := append(fullBoxHeader(0, 0), u32s(n)...)
+		for _, off := range t.ctts {
+			body = append(body, u32s(1, uint32(off))...)
+		}
+		stbl.children = append(stbl.children, leaf("ctts", body))
+	}
+	if t.sync != nil {
+		stbl.children = append(stbl.children, leaf("stss", append(fullBoxHeader(0, 0), u32s(append([]uint32{uint32(len(t.sync))}, t.sync...)...)...)))
+	}
+	stsz := append(fullBoxHeader(0, 0), u32s(0, n)...)
+	stbl.children = append(stbl.children,
+		leaf("stsc", append(fullBoxHeader(0, 0), u32s(1, 1, n, 1)...)),
+		leaf("stsz", append(stsz, u32s(t.sizes...)...)),
+		leaf("stco", append(fullBoxHeader(0, 0), u32s(1, chunkOffset)...)),
+	)
+	return box("trak",
+		leaf("tkhd", tkhdBody(t.id, t.width, t.height)),
+		box("mdia",
+			leaf("mdhd", append(fullBoxHeader(0, 0), u32s(0, 0, t.timescale, t.delta*n, 0)...)),
+			leaf("hdlr", hdlrBody(t.handler)),
+			box("minf", stbl),
+		),
+	)
+}
+
+// buildTestMP4 生成 ftyp + mdat + moov 的文件，mutate 可在编码前修改 moov
+func buildTestMP4(tracks []testTrack, mutate func(moov *mp4Box)) []byte {
+	ftyp := makeBox("ftyp", []byte("isom\x00\x00\x02\x00isomavc1"))
+	var mdat []byte
+	offsets := make([]uint32, len(tracks))
+	for i, t := range tracks {
+		offsets[i] = uint32(len(ftyp) + 8 + len(mdat))
+		for j, size := range t.sizes {
+			mdat = append(mdat, bytes.Repeat([]byte{sampleByte(t.id, j)}, int(size))...)
+		}
+	}
+	moov := box("moov", leaf("mvhd", mvhdBody(1000, 5000)))
+	for i, t := range tracks {
+		moov.children = append(moov.children, t.trakBox(offsets[i]))
+	}
+	if mutate != nil {
+		mutate(moov)
+	}
+	out := append(ftyp, makeBox("mdat", mdat)...)
+	return append(out, moov.encode()...)
+}
+
+// trakOf 按 handler 找到轨道
+func trakOf(moov *mp4Box, handler string) *mp4Box {
+	for _, c := range moov.children {
+		if c.typ != "trak" {
+			continue
+		}
+		if h := c.find("mdia", "hdlr"); h != nil && len(h.body) >= 12 && string(h.body[8:12]) == handler {
+			return c
+		}
+	}
+	return nil
+}
+
+func removeChild(parent *mp4Box, typ string) {
+	kept := parent.children[:0]
+	for _, c := range parent.children {
+		if c.typ != typ {
+			kept = append(kept, c)
+		}
+	}
+	parent.children = kept
+}
+
+func TestProbeMP4(t *testing.T) {
+	data := buildTestMP4([]testTrack{testVideoTrack(), testAudioTrack()}, nil)
+	meta, err := ProbeMP4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("probe valid file: %v", err)
+	}
+	want := VideoMeta{DurationMs: 5000, Width: 640, Height: 360, VideoCodec: "avc1", AudioCodec: "mp4a", Brand: "isom"}
+	if *meta != want {
+		t.Fatalf("meta = %+v, want %+v", *meta, want)
+	}
+}
+
+func TestProbeMP4SampleEntrySize(t *testing.T) {
+	// tkhd 宽高为 0 时使用 sample entry 中的宽高
+	data := buildTestMP4([]testTrack{testVideoTrack()}, func(moov *mp4Box) {
+		trakOf(moov, "vide").child("tkhd").body = tkhdBody(1, 0, 0)
+	})
+	meta, err := ProbeMP4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if meta.Width != 640 || meta.Height != 360 {
+		t.Fatalf("size = %dx%d, want 640x360", meta.Width, meta.Height)
+	}
+}
+
+func TestProbeMP4Invalid(t *testing.T) {
+	valid := buildTestMP4([]testTrack{testVideoTrack(), testAudioTrack()}, nil)
+	ftyp := makeBox("ftyp", []byte("isom\x00\x00\x02\x00isomavc1"))
+	moovOnly := valid[bytes.Index(valid, []byte("moov"))-4:]
+	mutated := func(mutate func(moov *mp4Box)) []byte {
+		return buildTestMP4([]testTrack{testVideoTrack(), testAudioTrack()}, mutate)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", []byte{0, 0, 0}},
+		{"truncated moov", valid[:len(valid)-10]},
+		{"truncated mid header", valid[:len(ftyp)+4]},
+		{"missing ftyp", moovOnly},
+		{"moov before ftyp", append(append([]byte{}, moovOnly...), ftyp...)},
+		{"duplicate ftyp", append(append([]byte{}, ftyp...), valid...)},
+		{"duplicate moov", append(append([]byte{}, valid...), moovOnly...)},
+		{"short ftyp", makeBox("ftyp", []byte("isom"))},
+		{"box smaller than header", append(append([]byte{}, ftyp...), 0, 0, 0, 4, 'f', 'r', 'e', 'e')},
+		{"box larger than file", append(append([]byte{}, ftyp...), 0, 0, 1, 0, 'f', 'r', 'e', 'e')},
+		{"non printable box type", append(append([]byte{}, ftyp...), 0, 0, 0, 8, 'f', 0, 'e', 'e')},
+		{"truncated largesize", append(append([]byte{}, ftyp...), 0, 0, 0, 1, 'f', 'r', 'e', 'e', 0, 0)},
+		{"largesize below header", append(append([]byte{}, ftyp...), append([]byte{0, 0, 0, 1, 'f', 'r', 'e', 'e'}, u32s(0, 8)...)...)},
+		{"missing moov", ftyp},
+		{"missing mvhd", mutated(func(moov *mp4Box) { removeChild(moov, "mvhd") })},
+		{"short mvhd", mutated(func(moov *mp4Box) { moov.child("mvhd").body = mvhdBody(1000, 5000)[:12] })},
+		{"unknown mvhd version", mutated(func(moov *mp4Box) { moov.child("mvhd").body[0] = 2 })},
+		{"zero timescale", mutated(func(moov *mp4Box) { moov.child("mvhd").body = mvhdBody(0, 5000) })},
+		{"zero duration", mutated(func(moov *mp4Box) { moov.child("mvhd").body = mvhdBody(1000, 0) })},
+		{"short tkhd", mutated(func(moov *mp4Box) { trakOf(moov, "vide").child("tkhd").body = tkhdBody(1, 640, 360)[:40] })},
+		{"short hdlr", mutated(func(moov *mp4Box) { trakOf(moov, "soun").find("mdia", "hdlr").body = []byte{0, 0, 0, 0} })},
+		{"empty stsd", mutated(func(moov *mp4Box) {
+			trakOf(moov, "vide").find("mdia", "minf", "stbl", "stsd").body = append(fullBoxHeader(0, 0), u32s(0)...)
+		})},
+		{"truncated stsd entry", mutated(func(moov *mp4Box) {
+			stsd := trakOf(moov, "vide").find("mdia", "minf", "stbl", "stsd")
+			stsd.body = stsd.body[:20]
+		})},
+		{"child box overflows parent", mutated(func(moov *mp4Box) {
+			// mdhd 的 size 字段比实际内容大，解析 mdia 子 box 时越界
+			mdia := trakOf(moov, "vide").child("mdia")
+			mdia.children = nil
+			mdia.body = append(u32s(64), []byte("mdhd")...)
+		})},
+		{"audio only", buildTestMP4([]testTrack{testAudioTrack()}, nil)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ProbeMP4(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if !errors.Is(err, ErrInvalidMP4) {
+				t.Fatalf("err = %v, want ErrInvalidMP4", err)
+			}
+		})
+	}
+}
+
+func TestProbeMP4FragmentedZeroDuration(t *testing.T) {
+	// 分片 MP4 的 mvhd 时长为 0 时仍然合法
+	data := buildTestMP4([]testTrack{testVideoTrack()}, func(moov *mp4Box) {
+		moov.child("mvhd").body = mvhdBody(1000, 0)
+		moov.children = append(moov.children, box("mvex"))
+	})
+	meta, err := ProbeMP4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if meta.DurationMs != 0 || meta.VideoCodec != "avc1" {
+		t.Fatalf("unexpected meta %+v", *meta)
+	}
+}
@@ -0,0 +1,96 @@
+package media
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaPathPrefix 媒体文件对外路径，由 video.MediaHandler 处理
+const MediaPathPrefix = "/media/"
+
+// URLSigner 根据存储 key 生成带 HMAC 签名、会过期的访问地址
+type URLSigner struct {
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+}
+
+func NewURLSigner(baseURL, secret string, ttl time.Duration) *URLSigner {
+	if ttl < 2*time.Minute {
+		ttl = time.Hour
+	}
+	if secret == "" {
+		secret = "change-me-in-config"
+	}
+	return &URLSigner{baseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret), ttl: ttl}
+}
+
+// IsExternal 非本站存储的地址（历史数据或外链）原样保存和输出
+func IsExternal(key string) bool {
+	return strings.Contains(key, "://")
+}
+
+// Sign 生成访问地址。过期时间按 ttl/2 对齐，同一时间窗内地址不变，便于 CDN 和浏览器缓存
+func (s *URLSigner) Sign(key string) string {
+	if key == "" || IsExternal(key) {
+		return key
+	}
+	half := int64(s.ttl/time.Second) / 2
+	exp := (time.Now().Unix()/half + 2) * half
+	q := url.Values{}
+	q.Set("exp", strconv.FormatInt(exp, 10))
+	q.Set("sig", s.signature(key, exp))
+	return s.baseURL + MediaPathPrefix + key + "?" + q.Encode()
+}
+
+// SignVariants 为缩略图 key 表逐个签名，空表返回 nil
+func (s *URLSigner) SignVariants(keys map[string]string) map[string]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(keys))
+	for label, key := range keys {
+		out[label] = s.Sign(key)
+	}
+	return out
+}
+
+// Verify 校验签名，返回签名的过期时间
+func (s *URLSigner) Verify(key, expStr, sig string) (time.Time, bool) {
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || sig == "" {
+		return time.Time{}, false
+	}
+	expiresAt := time.Unix(exp, 0)
+	if time.Now().After(expiresAt) {
+		return time.Time{}, false
+	}
+	if !hmac.Equal([]byte(s.signature(key, exp)), []byte(sig)) {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// KeyFromURL 从本站媒体地址（可能带签名参数）中取出 key
+func (s *URLSigner) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	key, ok := strings.CutPrefix(u.Path, MediaPathPrefix)
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *URLSigner) signature(key string, exp int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(exp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CoverVariantWidths 封面缩略图宽度，键名形如 720w
+var CoverVariantWidths = []int{720, 360, 120}
+
+const thumbnailJPEGQuality = 82
+
+// Flatten 转为不透明 RGBA，透明区域铺白底（JPEG 不支持透明）
+func Flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// Thumbnail 按宽度等比缩小，每个目标像素取对应源区域的平均值；不放大
+func Thumbnail(src *image.RGBA, width int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if width >= sw || width <= 0 {
+		return src
+	}
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		y0 := dy * sh / height
+		y1 := (dy + 1) * sh / height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < width; dx++ {
+			x0 := dx * sw / width
+			x1 := (dx + 1) * sw / width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, b, a, n uint32
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			o := dst.Pix[dy*dst.Stride+dx*4:]
+			o[0] = uint8(r / n)
+			o[1] = uint8(g / n)
+			o[2] = uint8(b / n)
+			o[3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: thumbnailJPEGQuality})
+}
+
+// VariantLabel 缩略图键名，如 720w
+func VariantLabel(width int) string {
+	return strconv.Itoa(width) + "w"
+}
+
+// VariantKey 缩略图与原图放在同一目录：<原 key>.<label>.jpg，可据此反查原图
+func VariantKey(key, label string) string {
+	return key + "." + label + ".jpg"
+}
+
+// VariantSource 从缩略图 key 反查原图 key
+func VariantSource(key string) (string, bool) {
+	rest, ok := strings.CutSuffix(key, "w.jpg")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndexByte(rest, '.')
+	if i <= 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(rest[i+1:]); err != nil {
+		return "", false
+	}
+	return rest[:i], true
+}
@@ -82,8 +82,12 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
 				return
 			}
-			c.Set("accountID", claims.AccountID)
-			c.Set("username", claims.Username)
+			// 暂停标记随暂停到期自动过期
+			if suspended, err := cache.Exists(cacheCtx, account.SuspendedCacheKey(claims.AccountID)); err == nil && suspended {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account has been suspended"})
+				return
+			}
+			setClaims(c, claims)
 			c.Next()
 			return
 		}
@@ -95,6 +99,14 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
 		return
 	}
+	if accountInfo.Banned {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account has been banned"})
+		return
+	}
+	if accountInfo.IsSuspended(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account has been suspended"})
+		return
+	}
 
 	if cache != nil {
 		cacheCtx, cancel := context.WithTimeout(c.Request.Context(), 50*time.Millisecond)
@@ -105,10 +117,18 @@ func check(c *gin.Context, claims *auth.Claims, tokenString string, accountRepo
 		}
 	}
 
-	c.Set("accountID", claims.AccountID)
-	c.Set("username", claims.Username)
+	setClaims(c, claims)
 	c.Next()
+}
 
+func setClaims(c *gin.Context, claims *auth.Claims) {
+	role := claims.Role
+	if role == "" {
+		role = account.RoleUser
+	}
+	c.Set("accountID", claims.AccountID)
+	c.Set("username", claims.Username)
+	c.Set("role", role)
 }
 
 func GetAccountID(c *gin.Context) (uint, error) {
@@ -124,3 +144,36 @@ func GetAccountID(c *gin.Context) (uint, error) {
 
 	return accountID, nil
 }
+
+// RequireRole only allows accounts whose token carries one of the given roles.
+// Must be used after JWTAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+	return func(c *gin.Context) {
+		role, err := GetRole(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := allowed[role]; !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func GetRole(c *gin.Context) (string, error) {
+	value, exists := c.Get("role")
+	if !exists {
+		return "", errors.New("role not found")
+	}
+	role, ok := value.(string)
+	if !ok {
+		return "", errors.New("role has invalid type")
+	}
+	return role, nil
+}
@@ -0,0 +1,67 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type CollectMQ struct {
+	*RabbitMQ
+}
+
+const (
+	collectExchange   = "collect.events"
+	collectQueue      = "collect.events"
+	collectBindingKey = "collect.*"
+
+	collectCollectRK   = "collect.collect"
+	collectUncollectRK = "collect.uncollect"
+)
+
+type CollectEvent struct {
+	EventID    string    `json:"event_id"`
+	Action     string    `json:"action"`
+	AccountID  uint      `json:"account_id"`
+	VideoID    uint      `json:"video_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func NewCollectMQ(base *RabbitMQ) (*CollectMQ, error) {
+	if base == nil {
+		return nil, errors.New("rabbitmq base is nil")
+	}
+	if err := base.DeclareTopic(collectExchange, collectQueue, collectBindingKey); err != nil {
+		return nil, err
+	}
+	return &CollectMQ{RabbitMQ: base}, nil
+}
+
+func (c *CollectMQ) Collect(ctx context.Context, accountID, videoID uint) error {
+	return c.publish(ctx, "collect", collectCollectRK, accountID, videoID)
+}
+
+func (c *CollectMQ) Uncollect(ctx context.Context, accountID, videoID uint) error {
+	return c.publish(ctx, "uncollect", collectUncollectRK, accountID, videoID)
+}
+
+func (c *CollectMQ) publish(ctx context.Context, action, routingKey string, accountID, videoID uint) error {
+	if c == nil || c.RabbitMQ == nil {
+		return errors.New("collect mq is not initialized")
+	}
+	if accountID == 0 || videoID == 0 {
+		return errors.New("accountID and videoID are required")
+	}
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+	evt := CollectEvent{
+		EventID:    id,
+		Action:     action,
+		AccountID:  accountID,
+		VideoID:    videoID,
+		OccurredAt: time.Now().UTC(),
+	}
+	return c.PublishJSON(ctx, collectExchange, routingKey, evt)
+}
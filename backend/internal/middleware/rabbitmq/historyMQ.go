@@ -0,0 +1,62 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type HistoryMQ struct {
+	*RabbitMQ
+}
+
+const (
+	historyExchange   = "history.events"
+	historyQueue      = "history.events"
+	historyBindingKey = "history.*"
+
+	historyWatchRK = "history.watch"
+)
+
+type HistoryEvent struct {
+	EventID    string    `json:"event_id"`
+	Action     string    `json:"action"`
+	AccountID  uint      `json:"account_id"`
+	VideoID    uint      `json:"video_id"`
+	PositionMs int64     `json:"position_ms"`
+	WatchedAt  time.Time `json:"watched_at"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func NewHistoryMQ(base *RabbitMQ) (*HistoryMQ, error) {
+	if base == nil {
+		return nil, errors.New("rabbitmq base is nil")
+	}
+	if err := base.DeclareTopic(historyExchange, historyQueue, historyBindingKey); err != nil {
+		return nil, err
+	}
+	return &HistoryMQ{RabbitMQ: base}, nil
+}
+
+func (h *HistoryMQ) Watch(ctx context.Context, accountID, videoID uint, positionMs int64, watchedAt time.Time) error {
+	if h == nil || h.RabbitMQ == nil {
+		return errors.New("history mq is not initialized")
+	}
+	if accountID == 0 || videoID == 0 {
+		return errors.New("accountID and videoID are required")
+	}
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+	evt := HistoryEvent{
+		EventID:    id,
+		Action:     "watch",
+		AccountID:  accountID,
+		VideoID:    videoID,
+		PositionMs: positionMs,
+		WatchedAt:  watchedAt.UTC(),
+		OccurredAt: time.Now().UTC(),
+	}
+	return h.PublishJSON(ctx, historyExchange, historyWatchRK, evt)
+}
@@ -0,0 +1,100 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type VideoMQ struct {
+	*RabbitMQ
+}
+
+const (
+	videoExchange   = "video.events"
+	videoQueue      = "video.events"
+	videoBindingKey = "video.*"
+
+	videoPublishedRK = "video.published"
+	videoDeletedRK   = "video.deleted"
+	videoUpdatedRK   = "video.updated"
+
+	// 打包耗时较长，使用单独的队列，避免阻塞其他视频事件
+	videoPackagingQueue = "video.packaging"
+	videoUploadedRK     = "video.uploaded"
+)
+
+type VideoEvent struct {
+	EventID     string    `json:"event_id"`
+	Action      string    `json:"action"`
+	VideoID     uint      `json:"video_id"`
+	AuthorID    uint      `json:"author_id"`
+	PlayKey     string    `json:"play_key,omitempty"`
+	CoverKey    string    `json:"cover_key,omitempty"`
+	OldCoverKey string    `json:"old_cover_key,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+func NewVideoMQ(base *RabbitMQ) (*VideoMQ, error) {
+	if base == nil {
+		return nil, errors.New("rabbitmq base is nil")
+	}
+	if err := base.DeclareTopic(videoExchange, videoQueue, videoBindingKey); err != nil {
+		return nil, err
+	}
+	if err := base.DeclareTopic(videoExchange, videoPackagingQueue, videoUploadedRK); err != nil {
+		return nil, err
+	}
+	return &VideoMQ{RabbitMQ: base}, nil
+}
+
+func (v *VideoMQ) Published(ctx context.Context, videoID, authorID uint) error {
+	return v.publish(ctx, "published", videoPublishedRK, VideoEvent{
+		VideoID:  videoID,
+		AuthorID: authorID,
+	})
+}
+
+func (v *VideoMQ) Deleted(ctx context.Context, videoID, authorID uint, playKey, coverKey string) error {
+	return v.publish(ctx, "deleted", videoDeletedRK, VideoEvent{
+		VideoID:  videoID,
+		AuthorID: authorID,
+		PlayKey:  playKey,
+		CoverKey: coverKey,
+	})
+}
+
+func (v *VideoMQ) Updated(ctx context.Context, videoID, authorID uint, coverKey, oldCoverKey string) error {
+	return v.publish(ctx, "updated", videoUpdatedRK, VideoEvent{
+		VideoID:     videoID,
+		AuthorID:    authorID,
+		CoverKey:    coverKey,
+		OldCoverKey: oldCoverKey,
+	})
+}
+
+// Uploaded 视频引用了新上传的文件，需要打包 HLS
+func (v *VideoMQ) Uploaded(ctx context.Context, videoID, authorID uint, playKey string) error {
+	return v.publish(ctx, "uploaded", videoUploadedRK, VideoEvent{
+		VideoID:  videoID,
+		AuthorID: authorID,
+		PlayKey:  playKey,
+	})
+}
+
+func (v *VideoMQ) publish(ctx context.Context, action, routingKey string, evt VideoEvent) error {
+	if v == nil || v.RabbitMQ == nil {
+		return errors.New("video mq is not initialized")
+	}
+	if evt.VideoID == 0 {
+		return errors.New("videoID is required")
+	}
+	id, err := newEventID(16)
+	if err != nil {
+		return err
+	}
+	evt.EventID = id
+	evt.Action = action
+	evt.OccurredAt = time.Now().UTC()
+	return v.PublishJSON(ctx, videoExchange, routingKey, evt)
+}
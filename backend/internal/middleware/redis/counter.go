@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// IncrWithTTL 计数加一，首次创建时设置过期时间
+func (c *Client) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if c == nil || c.rdb == nil {
+		return 0, nil
+	}
+	n, err := c.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		_ = c.rdb.Expire(ctx, key, ttl).Err()
+	}
+	return n, nil
+}
+
+func (c *Client) Decr(ctx context.Context, key string) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.Decr(ctx, key).Err()
+}
+
+// GetInt 读取计数，不存在时返回 0
+func (c *Client) GetInt(ctx context.Context, key string) (int64, error) {
+	if c == nil || c.rdb == nil {
+		return 0, nil
+	}
+	n, err := c.rdb.Get(ctx, key).Int64()
+	if IsMiss(err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// PFAdd 写入 HyperLogLog，返回基数估计是否变化（即元素大概率是新的）
+func (c *Client) PFAdd(ctx context.Context, key string, element string, ttl time.Duration) (bool, error) {
+	if c == nil || c.rdb == nil {
+		return false, nil
+	}
+	n, err := c.rdb.PFAdd(ctx, key, element).Result()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 && ttl > 0 {
+		_ = c.rdb.Expire(ctx, key, ttl).Err()
+	}
+	return n == 1, nil
+}
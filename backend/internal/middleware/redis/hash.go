@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const drainingInfix = ":draining:"
+
+func (c *Client) HIncrBy(ctx context.Context, key, field string, n int64) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.HIncrBy(ctx, key, field, n).Err()
+}
+
+// HIncrByFields 在一个事务中累加多个字段，要么全部生效要么都不生效，失败后可整体重试而不会重复累加
+func (c *Client) HIncrByFields(ctx context.Context, key string, fields map[string]int64) error {
+	if c == nil || c.rdb == nil || len(fields) == 0 {
+		return nil
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for field, n := range fields {
+			if n != 0 {
+				pipe.HIncrBy(ctx, key, field, n)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// HDrain 原子地取走整个 hash：先改名再读取删除，期间的新写入落到新的 key 上。
+// 临时 key 形如 <key>:draining:<改名时的毫秒时间戳>:<随机串>，进程在改名后退出时由 HRecoverDrains 找回
+func (c *Client) HDrain(ctx context.Context, key string) (map[string]string, error) {
+	if c == nil || c.rdb == nil {
+		return nil, nil
+	}
+	tmp, err := randToken(8)
+	if err != nil {
+		return nil, err
+	}
+	tmp = fmt.Sprintf("%s%s%d:%s", key, drainingInfix, time.Now().UnixMilli(), tmp)
+	if err := c.rdb.Rename(ctx, key, tmp).Err(); err != nil {
+		if err.Error() == "ERR no such key" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	values, err := c.rdb.HGetAll(ctx, tmp).Result()
+	if err != nil {
+		return nil, err
+	}
+	return values, c.rdb.Del(ctx, tmp).Err()
+}
+
+// HRecoverDrains 把 HDrain 改名后未能删除的临时 hash（改名早于 olderThan）累加回 key，返回找回的个数。
+// 只适用于字段值为整数的计数 hash；用 WATCH 保证同一个临时 key 只被找回一次
+func (c *Client) HRecoverDrains(ctx context.Context, key string, olderThan time.Duration) (int, error) {
+	if c == nil || c.rdb == nil {
+		return 0, nil
+	}
+	prefix := key + drainingInfix
+	cutoff := time.Now().Add(-olderThan).UnixMilli()
+	var (
+		cursor    uint64
+		recovered int
+	)
+	for {
+		keys, next, err := c.rdb.Scan(ctx, cursor, prefix+"*", 200).Result()
+		if err != nil {
+			return recovered, err
+		}
+		for _, tmp := range keys {
+			ts, _, _ := strings.Cut(strings.TrimPrefix(tmp, prefix), ":")
+			renamedAt, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil || renamedAt > cutoff {
+				continue
+			}
+			err = c.rdb.Watch(ctx, func(tx *redis.Tx) error {
+				values, err := tx.HGetAll(ctx, tmp).Result()
+				if err != nil {
+					return err
+				}
+				_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					for field, raw := range values {
+						if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n != 0 {
+							pipe.HIncrBy(ctx, key, field, n)
+						}
+					}
+					pipe.Del(ctx, tmp)
+					return nil
+				})
+				return err
+			}, tmp)
+			if errors.Is(err, redis.TxFailedErr) {
+				continue
+			}
+			if err != nil {
+				return recovered, err
+			}
+			recovered++
+		}
+		cursor = next
+		if cursor == 0 {
+			return recovered, nil
+		}
+	}
+}
+
+func (c *Client) HSet(ctx context.Context, key, field, value string) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.HSet(ctx, key, field, value).Err()
+}
+
+// HMGet 批量读取字段，不存在的字段不会出现在结果中
+func (c *Client) HMGet(ctx context.Context, key string, fields ...string) (map[string]string, error) {
+	if c == nil || c.rdb == nil || len(fields) == 0 {
+		return nil, nil
+	}
+	values, err := c.rdb.HMGet(ctx, key, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			out[fields[i]] = s
+		}
+	}
+	return out, nil
+}
+
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
+	if c == nil || c.rdb == nil || len(fields) == 0 {
+		return nil
+	}
+	return c.rdb.HDel(ctx, key, fields...).Err()
+}
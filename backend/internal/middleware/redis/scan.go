@@ -0,0 +1,31 @@
+package redis
+
+import "context"
+
+// DelByPattern 使用 SCAN 遍历并删除匹配的 key，避免 KEYS 阻塞
+func (c *Client) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	if c == nil || c.rdb == nil {
+		return 0, nil
+	}
+	var (
+		cursor  uint64
+		deleted int64
+	)
+	for {
+		keys, next, err := c.rdb.Scan(ctx, cursor, pattern, 200).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := c.rdb.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
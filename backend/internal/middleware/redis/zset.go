@@ -14,6 +14,20 @@ func (c *Client) ZincrBy(ctx context.Context, key string, member string, score f
 	return c.rdb.ZIncrBy(ctx, key, score, member).Err()
 }
 
+func (c *Client) ZRem(ctx context.Context, key string, members ...string) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		args = append(args, m)
+	}
+	return c.rdb.ZRem(ctx, key, args...).Err()
+}
+
 func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
 	if c == nil || c.rdb == nil {
 		return nil
@@ -57,3 +71,47 @@ func (c *Client) ZRevRangeByScore(ctx context.Context, key string, max, min stri
 		Count:  count,
 	}).Result()
 }
+
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+func (c *Client) ZAdd(ctx context.Context, key string, member string, score float64) error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZRevRangeWithScores 按分数从高到低返回成员和分数
+func (c *Client) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ScoredMember, error) {
+	if c == nil || c.rdb == nil {
+		return nil, nil
+	}
+	zs, err := c.rdb.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ScoredMember, 0, len(zs))
+	for _, z := range zs {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		out = append(out, ScoredMember{Member: member, Score: z.Score})
+	}
+	return out, nil
+}
+
+// ZRemRangeByRank 删除排名在 [start, stop] 的成员，返回被删除的成员
+func (c *Client) ZRemRangeByRank(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	if c == nil || c.rdb == nil {
+		return nil, nil
+	}
+	members, err := c.rdb.ZRange(ctx, key, start, stop).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	return members, c.rdb.ZRemRangeByRank(ctx, key, start, stop).Err()
+}
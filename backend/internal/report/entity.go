@@ -0,0 +1,73 @@
+package report
+
+import "time"
+
+const (
+	TargetVideo   = "video"
+	TargetComment = "comment"
+	TargetAccount = "account"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusResolved  = "resolved"
+	StatusDismissed = "dismissed"
+)
+
+const (
+	ActionNone          = "none"
+	ActionDismiss       = "dismiss"
+	ActionHideVideo     = "hide_video"
+	ActionDeleteComment = "delete_comment"
+	ActionBanAccount    = "ban_account"
+)
+
+// 举报原因码
+var reasonCodes = map[string]struct{}{
+	"spam":      {},
+	"abuse":     {},
+	"sexual":    {},
+	"violence":  {},
+	"illegal":   {},
+	"copyright": {},
+	"other":     {},
+}
+
+type Report struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ReporterID uint       `gorm:"not null;uniqueIndex:idx_report_reporter_target" json:"reporter_id"`
+	TargetType string     `gorm:"type:varchar(16);not null;uniqueIndex:idx_report_reporter_target;index:idx_report_target" json:"target_type"`
+	TargetID   uint       `gorm:"not null;uniqueIndex:idx_report_reporter_target;index:idx_report_target" json:"target_id"`
+	Reason     string     `gorm:"type:varchar(32);not null" json:"reason"`
+	Detail     string     `gorm:"type:varchar(512)" json:"detail,omitempty"`
+	Status     string     `gorm:"type:varchar(16);not null;default:pending;index" json:"status"`
+	Action     string     `gorm:"type:varchar(32)" json:"action,omitempty"`
+	HandlerID  uint       `json:"handler_id,omitempty"`
+	HandledAt  *time.Time `json:"handled_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	Reason     string `json:"reason"`
+	Detail     string `json:"detail"`
+}
+
+type ListReportsRequest struct {
+	Status     string `json:"status"`
+	TargetType string `json:"target_type"`
+	Limit      int    `json:"limit"`
+	IDBefore   uint   `json:"id_before"`
+}
+
+type ListReportsResponse struct {
+	Reports      []Report `json:"reports"`
+	NextIDBefore uint     `json:"next_id_before"`
+	HasMore      bool     `json:"has_more"`
+}
+
+type ResolveReportRequest struct {
+	ReportID uint   `json:"report_id"`
+	Action   string `json:"action"`
+}
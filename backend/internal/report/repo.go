@@ -0,0 +1,67 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *Report) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+func (r *ReportRepository) GetByID(ctx context.Context, id uint) (*Report, error) {
+	var report Report
+	if err := r.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *ReportRepository) CountPending(ctx context.Context, targetType string, targetID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, StatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *ReportRepository) List(ctx context.Context, status, targetType string, limit int, idBefore uint) ([]Report, error) {
+	var reports []Report
+	query := r.db.WithContext(ctx).Model(&Report{}).Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if idBefore > 0 {
+		query = query.Where("id < ?", idBefore)
+	}
+	if err := query.Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// 同一目标的所有待处理举报一起结案
+func (r *ReportRepository) ResolveTarget(ctx context.Context, targetType string, targetID uint, status, action string, handlerID uint) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, StatusPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"action":     action,
+			"handler_id": handlerID,
+			"handled_at": &now,
+		}).Error
+}
@@ -0,0 +1,236 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/video"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTarget   = errors.New("invalid target_type")
+	ErrInvalidReason   = errors.New("invalid reason")
+	ErrInvalidAction   = errors.New("invalid action")
+	ErrTargetNotFound  = errors.New("target not found")
+	ErrAlreadyReported = errors.New("already reported")
+	ErrAlreadyHandled  = errors.New("report already handled")
+)
+
+type ReportService struct {
+	repo           *ReportRepository
+	accountService *account.AccountService
+	videoService   *video.VideoService
+	videoRepo      *video.VideoRepository
+	commentRepo    *video.CommentRepository
+	hideThreshold  int
+}
+
+func NewReportService(repo *ReportRepository, accountService *account.AccountService, videoService *video.VideoService, videoRepo *video.VideoRepository, commentRepo *video.CommentRepository, hideThreshold int) *ReportService {
+	return &ReportService{
+		repo:           repo,
+		accountService: accountService,
+		videoService:   videoService,
+		videoRepo:      videoRepo,
+		commentRepo:    commentRepo,
+		hideThreshold:  hideThreshold,
+	}
+}
+
+func (s *ReportService) Create(ctx context.Context, report *Report) error {
+	if report == nil {
+		return errors.New("report is nil")
+	}
+	report.TargetType = strings.TrimSpace(report.TargetType)
+	report.Reason = strings.TrimSpace(report.Reason)
+	report.Detail = strings.TrimSpace(report.Detail)
+	if _, ok := reasonCodes[report.Reason]; !ok {
+		return ErrInvalidReason
+	}
+	if report.TargetID == 0 {
+		return ErrTargetNotFound
+	}
+	if err := s.checkTarget(ctx, report.TargetType, report.TargetID); err != nil {
+		return err
+	}
+
+	report.Status = StatusPending
+	if err := s.repo.Create(ctx, report); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrAlreadyReported
+		}
+		return err
+	}
+
+	// 达到阈值后自动隐藏，等待管理员复核
+	if s.hideThreshold > 0 {
+		count, err := s.repo.CountPending(ctx, report.TargetType, report.TargetID)
+		if err != nil {
+			log.Printf("report: failed to count pending reports: %v", err)
+			return nil
+		}
+		if count >= int64(s.hideThreshold) {
+			if err := s.autoHide(ctx, report.TargetType, report.TargetID); err != nil {
+				log.Printf("report: failed to auto hide %s %d: %v", report.TargetType, report.TargetID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ReportService) List(ctx context.Context, status, targetType string, limit int, idBefore uint) (ListReportsResponse, error) {
+	reports, err := s.repo.List(ctx, status, targetType, limit, idBefore)
+	if err != nil {
+		return ListReportsResponse{}, err
+	}
+	resp := ListReportsResponse{
+		Reports: reports,
+		HasMore: len(reports) == limit,
+	}
+	if len(reports) > 0 {
+		resp.NextIDBefore = reports[len(reports)-1].ID
+	}
+	return resp, nil
+}
+
+// 处理举报：执行处置动作，并将同一目标的待处理举报一起结案
+func (s *ReportService) Resolve(ctx context.Context, reportID uint, action string, handlerID uint, handlerRole string) error {
+	report, err := s.repo.GetByID(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status != StatusPending {
+		return ErrAlreadyHandled
+	}
+
+	status := StatusResolved
+	switch action {
+	case ActionNone:
+	case ActionDismiss:
+		// 误报：只恢复被举报自动隐藏的内容，人工下架的保持隐藏
+		status = StatusDismissed
+		if err := s.restoreAutoHidden(ctx, report.TargetType, report.TargetID); err != nil {
+			return err
+		}
+	case ActionHideVideo:
+		if report.TargetType != TargetVideo {
+			return ErrInvalidAction
+		}
+		if err := s.videoService.SetHidden(ctx, report.TargetID, true); err != nil {
+			return err
+		}
+	case ActionDeleteComment:
+		if report.TargetType != TargetComment {
+			return ErrInvalidAction
+		}
+		comment, err := s.commentRepo.GetByID(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+		if comment != nil {
+			if err := s.commentRepo.DeleteComment(ctx, comment); err != nil {
+				return err
+			}
+		}
+	case ActionBanAccount:
+		accountID, err := s.targetOwner(ctx, report.TargetType, report.TargetID)
+		if err != nil {
+			return err
+		}
+		// 与 /admin/account/ban 相同：不能封禁自己或角色不低于自己的账号
+		if err := s.accountService.CheckOperator(ctx, handlerID, handlerRole, accountID); err != nil {
+			return err
+		}
+		if err := s.accountService.SetBanned(ctx, accountID, true); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidAction
+	}
+	return s.repo.ResolveTarget(ctx, report.TargetType, report.TargetID, status, action, handlerID)
+}
+
+func (s *ReportService) checkTarget(ctx context.Context, targetType string, targetID uint) error {
+	switch targetType {
+	case TargetVideo:
+		ok, err := s.videoRepo.IsExist(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTargetNotFound
+		}
+	case TargetComment:
+		comment, err := s.commentRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if comment == nil {
+			return ErrTargetNotFound
+		}
+	case TargetAccount:
+		if _, err := s.accountService.FindByID(ctx, targetID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTargetNotFound
+			}
+			return err
+		}
+	default:
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+func (s *ReportService) autoHide(ctx context.Context, targetType string, targetID uint) error {
+	switch targetType {
+	case TargetVideo:
+		return s.videoService.AutoHide(ctx, targetID)
+	case TargetComment:
+		return s.commentRepo.HideIfVisible(ctx, targetID, video.HiddenByReport)
+	default:
+		// 账号不自动隐藏，只进入审核队列
+		return nil
+	}
+}
+
+func (s *ReportService) restoreAutoHidden(ctx context.Context, targetType string, targetID uint) error {
+	switch targetType {
+	case TargetVideo:
+		return s.videoService.RestoreAutoHidden(ctx, targetID)
+	case TargetComment:
+		return s.commentRepo.UnhideIfReason(ctx, targetID, video.HiddenByReport)
+	default:
+		return nil
+	}
+}
+
+// 举报目标对应的账号：视频/评论取作者
+func (s *ReportService) targetOwner(ctx context.Context, targetType string, targetID uint) (uint, error) {
+	switch targetType {
+	case TargetAccount:
+		return targetID, nil
+	case TargetVideo:
+		v, err := s.videoRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return 0, err
+		}
+		return v.AuthorID, nil
+	case TargetComment:
+		comment, err := s.commentRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return 0, err
+		}
+		if comment == nil {
+			return 0, ErrTargetNotFound
+		}
+		return comment.AuthorID, nil
+	default:
+		return 0, ErrInvalidTarget
+	}
+}
@@ -8,6 +8,16 @@ type Social struct {
 	VloggerID  uint `gorm:"not null;index:idx_social_vlogger;uniqueIndex:idx_social_follower_vlogger"`
 }
 
+// 观众与其他账号之间的关系
+const (
+	RelationNone       = "none"
+	RelationFollowing  = "following"
+	RelationFollowedBy = "followed-by"
+	RelationMutual     = "mutual"
+	// 对方账号已被平台封禁，不能关注
+	RelationBlocked = "blocked"
+)
+
 type FollowRequest struct {
 	VloggerID uint `json:"vlogger_id"`
 }
@@ -16,18 +26,80 @@ type UnfollowRequest struct {
 	VloggerID uint `json:"vlogger_id"`
 }
 
+// RelationAccount 关系列表中的账号，附带与当前观众之间的关注状态
+type RelationAccount struct {
+	account.PublicAccount
+	// 观众是否关注了该账号
+	IsFollowing bool `json:"is_following"`
+	// 该账号是否关注了观众
+	IsFollowedBy bool `json:"is_followed_by"`
+}
+
+type ListFollowersRequest struct {
+	// 不传则为自己
+	VloggerID uint `json:"vlogger_id"`
+	Limit     int  `json:"limit"`
+	// 游标：上一页返回的 next_before_id，按关注时间倒序
+	BeforeID uint `json:"before_id"`
+}
+
+type ListFollowersResponse struct {
+	Followers    []RelationAccount `json:"followers"`
+	Total        int64             `json:"total"`
+	NextBeforeID uint              `json:"next_before_id"`
+	HasMore      bool              `json:"has_more"`
+}
+
+// GetAllFollowersRequest 旧接口 getAllFollowers 的请求，一次返回全部粉丝；新代码请使用 listFollowers 分页
 type GetAllFollowersRequest struct {
 	VloggerID uint `json:"vlogger_id"`
 }
 
 type GetAllFollowersResponse struct {
-	Followers []*account.Account `json:"followers"`
+	Followers []RelationAccount `json:"followers"`
+}
+
+type ListFollowingRequest struct {
+	// 不传则为自己
+	FollowerID uint `json:"follower_id"`
+	Limit      int  `json:"limit"`
+	BeforeID   uint `json:"before_id"`
 }
 
+type ListFollowingResponse struct {
+	Following    []RelationAccount `json:"following"`
+	Total        int64             `json:"total"`
+	NextBeforeID uint              `json:"next_before_id"`
+	HasMore      bool              `json:"has_more"`
+}
+
+// GetAllVloggersRequest 旧接口 getAllVloggers 的请求，一次返回全部关注；新代码请使用 listFollowing 分页
 type GetAllVloggersRequest struct {
 	FollowerID uint `json:"follower_id"`
 }
 
 type GetAllVloggersResponse struct {
-	Vloggers []*account.Account `json:"vloggers"`
+	Vloggers []RelationAccount `json:"vloggers"`
+}
+
+type RelationRequest struct {
+	AccountIDs []uint `json:"account_ids"`
+}
+
+type RelationResponse struct {
+	// 账号 id 到关系的映射
+	Relations map[uint]string `json:"relations"`
+}
+
+type ListFriendsRequest struct {
+	// 不传则为自己
+	AccountID uint `json:"account_id"`
+	Limit     int  `json:"limit"`
+	BeforeID  uint `json:"before_id"`
+}
+
+type ListFriendsResponse struct {
+	Friends      []RelationAccount `json:"friends"`
+	NextBeforeID uint              `json:"next_before_id"`
+	HasMore      bool              `json:"has_more"`
 }
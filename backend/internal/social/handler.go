@@ -1,10 +1,12 @@
 package social
 
 import (
+	"errors"
 	"feedsystem_video_go/internal/middleware/jwt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type SocialHandler struct {
@@ -67,52 +69,155 @@ func (h *SocialHandler) Unfollow(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "unfollowed"})
 }
 
+// GetAllFollowers 旧接口，保留给未迁移到 listFollowers 的客户端
 func (h *SocialHandler) GetAllFollowers(c *gin.Context) {
 	var req GetAllFollowersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	vloggerID := req.VloggerID
-	if vloggerID == 0 {
-		accountID, err := jwt.GetAccountID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		vloggerID = accountID
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if req.VloggerID == 0 {
+		req.VloggerID = viewerAccountID
 	}
 
-	followers, err := h.service.GetAllFollowers(c.Request.Context(), vloggerID)
+	followers, err := h.service.GetAllFollowers(c.Request.Context(), viewerAccountID, req.VloggerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeListError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, GetAllFollowersResponse{Followers: followers})
 }
 
+// GetAllVloggers 旧接口，保留给未迁移到 listFollowing 的客户端
 func (h *SocialHandler) GetAllVloggers(c *gin.Context) {
 	var req GetAllVloggersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if req.FollowerID == 0 {
+		req.FollowerID = viewerAccountID
+	}
+
+	vloggers, err := h.service.GetAllVloggers(c.Request.Context(), viewerAccountID, req.FollowerID)
+	if err != nil {
+		writeListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, GetAllVloggersResponse{Vloggers: vloggers})
+}
+
+func (h *SocialHandler) ListFollowers(c *gin.Context) {
+	var req ListFollowersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if req.VloggerID == 0 {
+		req.VloggerID = viewerAccountID
+	}
+
+	resp, err := h.service.ListFollowers(c.Request.Context(), viewerAccountID, req)
+	if err != nil {
+		writeListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *SocialHandler) ListFollowing(c *gin.Context) {
+	var req ListFollowingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if req.FollowerID == 0 {
+		req.FollowerID = viewerAccountID
+	}
+
+	resp, err := h.service.ListFollowing(c.Request.Context(), viewerAccountID, req)
+	if err != nil {
+		writeListError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *SocialHandler) ListFriends(c *gin.Context) {
+	var req ListFriendsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == 0 {
+		req.AccountID = viewerAccountID
+	}
 
-	followerID := req.FollowerID
-	if followerID == 0 {
-		accountID, err := jwt.GetAccountID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		followerID = accountID
+	resp, err := h.service.ListFriends(c.Request.Context(), viewerAccountID, req)
+	if err != nil {
+		writeListError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	vloggers, err := h.service.GetAllVloggers(c.Request.Context(), followerID)
+func (h *SocialHandler) Relation(c *gin.Context) {
+	var req RelationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.AccountIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account_ids is required"})
+		return
+	}
+	if len(req.AccountIDs) > maxRelationBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many account_ids"})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	relations, err := h.service.Relations(c.Request.Context(), viewerAccountID, req.AccountIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, GetAllVloggersResponse{Vloggers: vloggers})
+	c.JSON(http.StatusOK, RelationResponse{Relations: relations})
+}
+
+func writeListError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
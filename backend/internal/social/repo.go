@@ -2,8 +2,10 @@ package social
 
 import (
 	"context"
+	"errors"
 	"feedsystem_video_go/internal/account"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
@@ -15,68 +17,121 @@ func NewSocialRepository(db *gorm.DB) *SocialRepository {
 	return &SocialRepository{db: db}
 }
 
-func (r *SocialRepository) Follow(ctx context.Context, social *Social) error {
-	return r.db.WithContext(ctx).Create(social).Error
+// Follow 写入关注关系并增加双方的关注/粉丝计数；关系已存在时返回 false，计数不变
+func (r *SocialRepository) Follow(ctx context.Context, social *Social) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(social).Error; err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return nil
+			}
+			return err
+		}
+		created = true
+		return changeFollowCounters(tx, social, 1)
+	})
+	return created, err
 }
 
-func (r *SocialRepository) Unfollow(ctx context.Context, social *Social) error {
-	return r.db.WithContext(ctx).
-		Where("follower_id = ? AND vlogger_id = ?", social.FollowerID, social.VloggerID).
-		Delete(&Social{}).Error
+// Unfollow 删除关注关系并减少计数；关系不存在时返回 false
+func (r *SocialRepository) Unfollow(ctx context.Context, social *Social) (bool, error) {
+	deleted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND vlogger_id = ?", social.FollowerID, social.VloggerID).
+			Delete(&Social{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		return changeFollowCounters(tx, social, -1)
+	})
+	return deleted, err
 }
 
-func (r *SocialRepository) GetAllFollowers(ctx context.Context, VloggerID uint) ([]*account.Account, error) {
-	var relations []Social
-	if err := r.db.WithContext(ctx).
-		Model(&Social{}).
-		Where("vlogger_id = ?", VloggerID).
-		Find(&relations).Error; err != nil {
-		return nil, err
+func changeFollowCounters(tx *gorm.DB, social *Social, delta int64) error {
+	if err := account.ChangeCounter(tx, social.FollowerID, account.CounterFollowing, delta); err != nil {
+		return err
 	}
+	return account.ChangeCounter(tx, social.VloggerID, account.CounterFollowers, delta)
+}
+
+// ListFollowers 按关注时间倒序分页，beforeID 为上一页最后一条关系的 id
+func (r *SocialRepository) ListFollowers(ctx context.Context, vloggerID, beforeID uint, limit int) ([]Social, error) {
+	return r.list(ctx, "vlogger_id", vloggerID, beforeID, limit)
+}
 
-	followerIDs := make([]uint, 0, len(relations))
-	for _, rel := range relations {
-		followerIDs = append(followerIDs, rel.FollowerID)
+func (r *SocialRepository) ListFollowing(ctx context.Context, followerID, beforeID uint, limit int) ([]Social, error) {
+	return r.list(ctx, "follower_id", followerID, beforeID, limit)
+}
+
+// ListFriends 互相关注的账号，按 accountID 关注对方的时间倒序，beforeID 为上一页最后一条关系的 id
+func (r *SocialRepository) ListFriends(ctx context.Context, accountID, beforeID uint, limit int) ([]Social, error) {
+	var relations []Social
+	query := r.db.WithContext(ctx).
+		Select("socials.*").
+		Joins("JOIN socials AS back ON back.follower_id = socials.vlogger_id AND back.vlogger_id = socials.follower_id").
+		Where("socials.follower_id = ?", accountID)
+	if beforeID > 0 {
+		query = query.Where("socials.id < ?", beforeID)
 	}
-	if len(followerIDs) == 0 {
-		return []*account.Account{}, nil
+	if err := query.Order("socials.id desc").Limit(limit).Find(&relations).Error; err != nil {
+		return nil, err
 	}
+	return relations, nil
+}
 
-	var followers []*account.Account
-	if err := r.db.WithContext(ctx).
-		Model(&account.Account{}).
-		Where("id IN ?", followerIDs).
-		Find(&followers).Error; err != nil {
+func (r *SocialRepository) list(ctx context.Context, column string, accountID, beforeID uint, limit int) ([]Social, error) {
+	var relations []Social
+	query := r.db.WithContext(ctx).Where(column+" = ?", accountID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if err := query.Order("id desc").Limit(limit).Find(&relations).Error; err != nil {
 		return nil, err
 	}
-	return followers, nil
+	return relations, nil
 }
 
-func (r *SocialRepository) GetAllVloggers(ctx context.Context, FollowerID uint) ([]*account.Account, error) {
-	var relations []Social
+// FollowingAmong vloggerIDs 中被 followerID 关注的账号
+func (r *SocialRepository) FollowingAmong(ctx context.Context, followerID uint, vloggerIDs []uint) (map[uint]bool, error) {
+	result := make(map[uint]bool)
+	if followerID == 0 || len(vloggerIDs) == 0 {
+		return result, nil
+	}
+	var ids []uint
 	if err := r.db.WithContext(ctx).
 		Model(&Social{}).
-		Where("follower_id = ?", FollowerID).
-		Find(&relations).Error; err != nil {
+		Where("follower_id = ? AND vlogger_id IN ?", followerID, vloggerIDs).
+		Pluck("vlogger_id", &ids).Error; err != nil {
 		return nil, err
 	}
-
-	vloggerIDs := make([]uint, 0, len(relations))
-	for _, rel := range relations {
-		vloggerIDs = append(vloggerIDs, rel.VloggerID)
-	}
-	if len(vloggerIDs) == 0 {
-		return []*account.Account{}, nil
+	for _, id := range ids {
+		result[id] = true
 	}
+	return result, nil
+}
 
-	var vloggers []*account.Account
+// FollowersAmong followerIDs 中关注了 vloggerID 的账号
+func (r *SocialRepository) FollowersAmong(ctx context.Context, vloggerID uint, followerIDs []uint) (map[uint]bool, error) {
+	result := make(map[uint]bool)
+	if vloggerID == 0 || len(followerIDs) == 0 {
+		return result, nil
+	}
+	var ids []uint
 	if err := r.db.WithContext(ctx).
-		Model(&account.Account{}).
-		Where("id IN ?", vloggerIDs).
-		Find(&vloggers).Error; err != nil {
+		Model(&Social{}).
+		Where("vlogger_id = ? AND follower_id IN ?", vloggerID, followerIDs).
+		Pluck("follower_id", &ids).Error; err != nil {
 		return nil, err
 	}
-	return vloggers, nil
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
 }
 
 func (r *SocialRepository) IsFollowed(ctx context.Context, social *Social) (bool, error) {
@@ -89,3 +144,14 @@ func (r *SocialRepository) IsFollowed(ctx context.Context, social *Social) (bool
 	}
 	return count > 0, nil
 }
+
+func (r *SocialRepository) ListFollowerIDs(ctx context.Context, vloggerID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.WithContext(ctx).
+		Model(&Social{}).
+		Where("vlogger_id = ?", vloggerID).
+		Pluck("follower_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
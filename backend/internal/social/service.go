@@ -11,10 +11,26 @@ type SocialService struct {
 	repo        *SocialRepository
 	accountrepo *account.AccountRepository
 	socialMQ    *rabbitmq.SocialMQ
+	avatars     account.AvatarMedia
 }
 
-func NewSocialService(repo *SocialRepository, accountrepo *account.AccountRepository, socialMQ *rabbitmq.SocialMQ) *SocialService {
-	return &SocialService{repo: repo, accountrepo: accountrepo, socialMQ: socialMQ}
+func NewSocialService(repo *SocialRepository, accountrepo *account.AccountRepository, socialMQ *rabbitmq.SocialMQ, avatars account.AvatarMedia) *SocialService {
+	return &SocialService{repo: repo, accountrepo: accountrepo, socialMQ: socialMQ, avatars: avatars}
+}
+
+// 单次查询关系的账号数上限
+const maxRelationBatch = 100
+
+const maxSocialPageLimit = 50
+
+func socialPageLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > maxSocialPageLimit {
+		return maxSocialPageLimit
+	}
+	return limit
 }
 
 func (s *SocialService) Follow(ctx context.Context, social *Social) error {
@@ -37,9 +53,19 @@ func (s *SocialService) Follow(ctx context.Context, social *Social) error {
 		return errors.New("already followed")
 	}
 	if s.socialMQ != nil {
-		s.socialMQ.Follow(ctx, social.FollowerID, social.VloggerID)
+		if err := s.socialMQ.Follow(ctx, social.FollowerID, social.VloggerID); err == nil {
+			return nil
+		}
+	}
+	// Fallback: MQ 不可用时直接写库
+	created, err := s.repo.Follow(ctx, social)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errors.New("already followed")
 	}
-	return s.repo.Follow(ctx, social)
+	return nil
 }
 
 func (s *SocialService) Unfollow(ctx context.Context, social *Social) error {
@@ -59,25 +85,221 @@ func (s *SocialService) Unfollow(ctx context.Context, social *Social) error {
 		return errors.New("not followed")
 	}
 	if s.socialMQ != nil {
-		s.socialMQ.UnFollow(ctx, social.FollowerID, social.VloggerID)
+		if err := s.socialMQ.UnFollow(ctx, social.FollowerID, social.VloggerID); err == nil {
+			return nil
+		}
 	}
-	return s.repo.Unfollow(ctx, social)
+	// Fallback: MQ 不可用时直接写库
+	deleted, err := s.repo.Unfollow(ctx, social)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("not followed")
+	}
+	return nil
 }
 
-func (s *SocialService) GetAllFollowers(ctx context.Context, VloggerID uint) ([]*account.Account, error) {
-	_, err := s.accountrepo.FindByID(ctx, VloggerID)
+func (s *SocialService) ListFollowers(ctx context.Context, viewerAccountID uint, req ListFollowersRequest) (*ListFollowersResponse, error) {
+	vlogger, err := s.accountrepo.FindByID(ctx, req.VloggerID)
+	if err != nil {
+		return nil, err
+	}
+	limit := socialPageLimit(req.Limit)
+	relations, err := s.repo.ListFollowers(ctx, vlogger.ID, req.BeforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListFollowersResponse{Followers: []RelationAccount{}, Total: vlogger.FollowersCount}
+	if len(relations) > limit {
+		relations = relations[:limit]
+		resp.HasMore = true
+	}
+	if len(relations) == 0 {
+		return resp, nil
+	}
+	resp.NextBeforeID = relations[len(relations)-1].ID
+
+	ids := make([]uint, 0, len(relations))
+	for _, rel := range relations {
+		ids = append(ids, rel.FollowerID)
+	}
+	resp.Followers, err = s.relationAccounts(ctx, viewerAccountID, ids)
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetAllFollowers(ctx, VloggerID)
+	return resp, nil
 }
 
-func (s *SocialService) GetAllVloggers(ctx context.Context, FollowerID uint) ([]*account.Account, error) {
-	_, err := s.accountrepo.FindByID(ctx, FollowerID)
+func (s *SocialService) ListFollowing(ctx context.Context, viewerAccountID uint, req ListFollowingRequest) (*ListFollowingResponse, error) {
+	follower, err := s.accountrepo.FindByID(ctx, req.FollowerID)
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetAllVloggers(ctx, FollowerID)
+	limit := socialPageLimit(req.Limit)
+	relations, err := s.repo.ListFollowing(ctx, follower.ID, req.BeforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListFollowingResponse{Following: []RelationAccount{}, Total: follower.FollowingCount}
+	if len(relations) > limit {
+		relations = relations[:limit]
+		resp.HasMore = true
+	}
+	if len(relations) == 0 {
+		return resp, nil
+	}
+	resp.NextBeforeID = relations[len(relations)-1].ID
+
+	ids := make([]uint, 0, len(relations))
+	for _, rel := range relations {
+		ids = append(ids, rel.VloggerID)
+	}
+	resp.Following, err = s.relationAccounts(ctx, viewerAccountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAllFollowers 兼容旧接口：逐页读取 ListFollowers 直到取完
+func (s *SocialService) GetAllFollowers(ctx context.Context, viewerAccountID, vloggerID uint) ([]RelationAccount, error) {
+	followers := []RelationAccount{}
+	req := ListFollowersRequest{VloggerID: vloggerID, Limit: maxSocialPageLimit}
+	for {
+		resp, err := s.ListFollowers(ctx, viewerAccountID, req)
+		if err != nil {
+			return nil, err
+		}
+		followers = append(followers, resp.Followers...)
+		if !resp.HasMore {
+			return followers, nil
+		}
+		req.BeforeID = resp.NextBeforeID
+	}
+}
+
+// GetAllVloggers 兼容旧接口：逐页读取 ListFollowing 直到取完
+func (s *SocialService) GetAllVloggers(ctx context.Context, viewerAccountID, followerID uint) ([]RelationAccount, error) {
+	vloggers := []RelationAccount{}
+	req := ListFollowingRequest{FollowerID: followerID, Limit: maxSocialPageLimit}
+	for {
+		resp, err := s.ListFollowing(ctx, viewerAccountID, req)
+		if err != nil {
+			return nil, err
+		}
+		vloggers = append(vloggers, resp.Following...)
+		if !resp.HasMore {
+			return vloggers, nil
+		}
+		req.BeforeID = resp.NextBeforeID
+	}
+}
+
+func (s *SocialService) ListFriends(ctx context.Context, viewerAccountID uint, req ListFriendsRequest) (*ListFriendsResponse, error) {
+	if _, err := s.accountrepo.FindByID(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+	limit := socialPageLimit(req.Limit)
+	relations, err := s.repo.ListFriends(ctx, req.AccountID, req.BeforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListFriendsResponse{Friends: []RelationAccount{}}
+	if len(relations) > limit {
+		relations = relations[:limit]
+		resp.HasMore = true
+	}
+	if len(relations) == 0 {
+		return resp, nil
+	}
+	resp.NextBeforeID = relations[len(relations)-1].ID
+
+	ids := make([]uint, 0, len(relations))
+	for _, rel := range relations {
+		ids = append(ids, rel.VloggerID)
+	}
+	resp.Friends, err = s.relationAccounts(ctx, viewerAccountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Relations 观众与一批账号之间的关系；被封禁的账号为 blocked，观众自己、未登录和不存在的账号为 none
+func (s *SocialService) Relations(ctx context.Context, viewerAccountID uint, ids []uint) (map[uint]string, error) {
+	relations := make(map[uint]string, len(ids))
+	others := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := relations[id]; ok || id == 0 {
+			continue
+		}
+		relations[id] = RelationNone
+		if id != viewerAccountID {
+			others = append(others, id)
+		}
+	}
+	if viewerAccountID == 0 || len(others) == 0 {
+		return relations, nil
+	}
+	following, err := s.repo.FollowingAmong(ctx, viewerAccountID, others)
+	if err != nil {
+		return nil, err
+	}
+	followedBy, err := s.repo.FollowersAmong(ctx, viewerAccountID, others)
+	if err != nil {
+		return nil, err
+	}
+	banned, err := s.accountrepo.BannedAmong(ctx, others)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range others {
+		switch {
+		case banned[id]:
+			relations[id] = RelationBlocked
+		case following[id] && followedBy[id]:
+			relations[id] = RelationMutual
+		case following[id]:
+			relations[id] = RelationFollowing
+		case followedBy[id]:
+			relations[id] = RelationFollowedBy
+		}
+	}
+	return relations, nil
+}
+
+// relationAccounts 按 ids 顺序加载账号，并标注与观众之间的关注状态；已删除的账号跳过
+func (s *SocialService) relationAccounts(ctx context.Context, viewerAccountID uint, ids []uint) ([]RelationAccount, error) {
+	accounts, err := s.accountrepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*account.Account, len(accounts))
+	for i := range accounts {
+		byID[accounts[i].ID] = &accounts[i]
+	}
+	following, err := s.repo.FollowingAmong(ctx, viewerAccountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	followedBy, err := s.repo.FollowersAmong(ctx, viewerAccountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]RelationAccount, 0, len(ids))
+	for _, id := range ids {
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		items = append(items, RelationAccount{
+			PublicAccount: account.NewPublicAccount(a, s.avatars),
+			IsFollowing:   following[id],
+			IsFollowedBy:  followedBy[id],
+		})
+	}
+	return items, nil
 }
 
 func (s *SocialService) IsFollowed(ctx context.Context, social *Social) (bool, error) {
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Local 本地磁盘存储，公开地址为 BaseURL/key，直传地址指向 /storage/object/key
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocal(cfg config.LocalStorageConfig) *Local {
+	root := cfg.Root
+	if root == "" {
+		root = filepath.Join(".run", "uploads")
+	}
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "/static"
+	}
+	secret := cfg.Secret
+	if secret == "" {
+		secret = "change-me-in-config"
+	}
+	return &Local{root: root, baseURL: baseURL, secret: []byte(secret)}
+}
+
+func (l *Local) Root() string {
+	return l.root
+}
+
+// BasePath 公开地址的路径前缀，例如 /static
+func (l *Local) BasePath() string {
+	if u, err := url.Parse(l.baseURL); err == nil && u.Path != "" {
+		return strings.TrimRight(u.Path, "/")
+	}
+	return "/static"
+}
+
+func (l *Local) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return l.put(key, r, size, true)
+}
+
+// PutNew 与 Put 相同但不覆盖已有文件，key 已存在时返回 ErrObjectExists
+func (l *Local) PutNew(ctx context.Context, key string, r io.Reader, size int64) error {
+	return l.put(key, r, size, false)
+}
+
+func (l *Local) put(key string, r io.Reader, size int64, overwrite bool) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".put-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("size mismatch: expected %d, got %d", size, n)
+	}
+	if overwrite {
+		return os.Rename(tmp.Name(), p)
+	}
+	// 硬链接在目标已存在时失败，检查和创建是原子的
+	if err := os.Link(tmp.Name(), p); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrObjectExists
+		}
+		return err
+	}
+	return nil
+}
+
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+func (o *localObject) Info() ObjectInfo {
+	return o.info
+}
+
+func (l *Local) Open(ctx context.Context, key string) (Object, error) {
+	info, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	p, _ := l.path(key)
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &localObject{File: f, info: info}, nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+	if fi.IsDir() {
+		return ObjectInfo{}, ErrNotFound
+	}
+	return ObjectInfo{
+		Key:          key,
+		Size:         fi.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		LastModified: fi.ModTime(),
+		ETag:         fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()),
+	}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历本地目录，跳过写入中的临时文件
+func (l *Local) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	dir := filepath.Join(l.root, filepath.FromSlash(strings.TrimLeft(prefix, "/")))
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		info, err := l.Stat(ctx, filepath.ToSlash(rel))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		return fn(info)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + strings.TrimLeft(key, "/")
+}
+
+func (l *Local) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(u.Path, l.BasePath()+"/")
+	if !ok {
+		return "", false
+	}
+	key, err := CleanKey(rel)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+func (l *Local) PresignPut(ctx context.Context, key string, contentType string, size int64, ttl time.Duration) (string, error) {
+	if size <= 0 {
+		return "", fmt.Errorf("invalid size %d", size)
+	}
+	return l.presign(http.MethodPut, key, size, ttl)
+}
+
+func (l *Local) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return l.presign(http.MethodGet, key, 0, ttl)
+}
+
+// presign size 只对 PUT 有意义，GET 传 0 且不出现在地址中
+func (l *Local) presign(method, key string, size int64, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		return "", ErrInvalidTTL
+	}
+	exp := time.Now().Add(ttl).Unix()
+	q := url.Values{}
+	q.Set("exp", strconv.FormatInt(exp, 10))
+	if size > 0 {
+		q.Set("size", strconv.FormatInt(size, 10))
+	}
+	q.Set("sig", l.sign(method, key, exp, size))
+	return "/storage/object/" + key + "?" + q.Encode(), nil
+}
+
+func (l *Local) sign(method, key string, exp, size int64) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + strconv.FormatInt(exp, 10) + "\n" + strconv.FormatInt(size, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验签名和有效期，返回签名时声明的大小（GET 为 0）
+func (l *Local) verify(method, key string, q url.Values) (int64, bool) {
+	exp, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return 0, false
+	}
+	var size int64
+	if raw := q.Get("size"); raw != "" {
+		if size, err = strconv.ParseInt(raw, 10, 64); err != nil || size <= 0 {
+			return 0, false
+		}
+	}
+	return size, hmac.Equal([]byte(l.sign(method, key, exp, size)), []byte(q.Get("sig")))
+}
+
+// LocalHandler 处理本地存储的预签名直传(PUT)和直读(GET)
+type LocalHandler struct {
+	local   *Local
+	maxSize int64
+}
+
+func NewLocalHandler(local *Local, maxSize int64) *LocalHandler {
+	return &LocalHandler{local: local, maxSize: maxSize}
+}
+
+func (h *LocalHandler) PutObject(c *gin.Context) {
+	key, err := CleanKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	size, ok := h.local.verify(http.MethodPut, key, c.Request.URL.Query())
+	if !ok || size == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid or expired signature"})
+		return
+	}
+	// 只接受签名时声明的大小，且不能覆盖已有对象，避免发布后重放直传地址替换已校验的内容
+	if c.Request.ContentLength != size || size > h.maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
+		return
+	}
+	if _, err := h.local.Stat(c.Request.Context(), key); err == nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": ErrObjectExists.Error()})
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize)
+	if err := h.local.PutNew(c.Request.Context(), key, body, size); err != nil {
+		if errors.Is(err, ErrObjectExists) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"key": key})
+}
+
+func (h *LocalHandler) GetObject(c *gin.Context) {
+	key, err := CleanKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := h.local.verify(http.MethodGet, key, c.Request.URL.Query()); !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid or expired signature"})
+		return
+	}
+	obj, err := h.local.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer obj.Close()
+	info := obj.Info()
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.LastModified, obj)
+}
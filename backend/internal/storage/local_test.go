@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newLocalServer(t *testing.T) (*Local, *httptest.Server) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	local := NewLocal(config.LocalStorageConfig{Root: t.TempDir(), Secret: "test-secret"})
+	h := NewLocalHandler(local, 1<<20)
+	r := gin.New()
+	r.PUT("/storage/object/*key", h.PutObject)
+	r.GET("/storage/object/*key", h.GetObject)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return local, srv
+}
+
+func TestLocalPresignedPutOnce(t *testing.T) {
+	local, srv := newLocalServer(t)
+	ctx := context.Background()
+	key := "videos/1/20250101/a.mp4"
+
+	putURL, err := local.PresignPut(ctx, key, "video/mp4", 7, time.Minute)
+	if err != nil {
+		t.Fatalf("presign put: %v", err)
+	}
+	if status := presignedPut(t, srv.URL+putURL, "payload", "video/mp4"); status != http.StatusOK {
+		t.Fatalf("presigned put status = %d", status)
+	}
+	// 重放同一地址不能覆盖已上传（可能已校验并发布）的内容
+	if status := presignedPut(t, srv.URL+putURL, "replace", "video/mp4"); status != http.StatusPreconditionFailed {
+		t.Fatalf("replayed put status = %d, want 412", status)
+	}
+	obj, err := local.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	if string(data) != "payload" {
+		t.Fatalf("object = %q, want original payload", data)
+	}
+
+	// 签名中的大小和 key 都不能改
+	other, _ := local.PresignPut(ctx, "videos/1/20250101/b.mp4", "video/mp4", 7, time.Minute)
+	if status := presignedPut(t, srv.URL+other, "longer payload", "video/mp4"); status != http.StatusBadRequest {
+		t.Errorf("wrong size status = %d, want 400", status)
+	}
+	if status := presignedPut(t, srv.URL+strings.Replace(other, "size=7", "size=14", 1), "longer payload", "video/mp4"); status != http.StatusForbidden {
+		t.Errorf("tampered size status = %d, want 403", status)
+	}
+	if status := presignedPut(t, srv.URL+strings.Replace(other, "b.mp4", "a.mp4", 1), "payload", "video/mp4"); status != http.StatusForbidden {
+		t.Errorf("tampered key status = %d, want 403", status)
+	}
+
+	// GET 地址不能当作 PUT 地址使用
+	getURL, err := local.PresignGet(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("presign get: %v", err)
+	}
+	if status := presignedPut(t, srv.URL+getURL, "payload", "video/mp4"); status != http.StatusForbidden {
+		t.Errorf("put with get url status = %d, want 403", status)
+	}
+	if body, status := httpGet(t, srv.URL+getURL); status != http.StatusOK || body != "payload" {
+		t.Fatalf("presigned get = %d %q", status, body)
+	}
+}
+
+func TestLocalPutNew(t *testing.T) {
+	local := NewLocal(config.LocalStorageConfig{Root: t.TempDir()})
+	ctx := context.Background()
+	if err := local.PutNew(ctx, "covers/1/a.jpg", strings.NewReader("one"), 3); err != nil {
+		t.Fatalf("put new: %v", err)
+	}
+	if err := local.PutNew(ctx, "covers/1/a.jpg", strings.NewReader("two"), 3); !errors.Is(err, ErrObjectExists) {
+		t.Fatalf("second put new: err = %v, want ErrObjectExists", err)
+	}
+	// Put 仍然允许服务端覆盖写
+	if err := local.Put(ctx, "covers/1/a.jpg", strings.NewReader("two"), 3, ""); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+// fakeS3 进程内的 S3 替身：path-style 访问，按服务端视角独立校验 SigV4（Authorization 头和预签名查询参数）
+type fakeS3 struct {
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	// List 每页返回的对象数，用于覆盖 continuation-token 分页
+	pageSize int
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	f := &fakeS3{
+		bucket:    "feedsystem",
+		region:    "us-east-1",
+		accessKey: "test-access",
+		secretKey: "test-secret",
+		pageSize:  2,
+		objects:   make(map[string]fakeObject),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) client(t *testing.T, endpoint string) *S3 {
+	t.Helper()
+	s, err := NewS3(config.S3StorageConfig{
+		Endpoint:  endpoint,
+		Region:    f.region,
+		Bucket:    f.bucket,
+		AccessKey: f.accessKey,
+		SecretKey: f.secretKey,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	return s
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.authenticate(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket)
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(rest, "/")
+	if key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		if _, exists := f.objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+			http.Error(w, "PreconditionFailed", http.StatusPreconditionFailed)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modified: time.Now().UTC()}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead, http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		data, status := obj.data, http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			if err != nil || start >= len(data) {
+				http.Error(w, "InvalidRange", http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			data, status = data[start:], http.StatusPartialContent
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sha256.Sum256(obj.data)))
+		w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prefix := q.Get("prefix")
+	f.mu.Lock()
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	start := 0
+	if token := q.Get("continuation-token"); token != "" {
+		start, _ = strconv.Atoi(token)
+	}
+	end := min(start+f.pageSize, len(keys))
+
+	type content struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	}
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}{IsTruncated: end < len(keys)}
+	for _, k := range keys[start:end] {
+		obj := f.objects[k]
+		result.Contents = append(result.Contents, content{Key: k, LastModified: obj.modified, Size: int64(len(obj.data))})
+	}
+	f.mu.Unlock()
+	if result.IsTruncated {
+		result.NextContinuationToken = strconv.Itoa(end)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// authenticate 按收到的请求重建 canonical request 并校验签名
+func (f *fakeS3) authenticate(r *http.Request) error {
+	q := r.URL.Query()
+	var (
+		credential, signedHeaders, signature, amzDate, payload string
+	)
+	if sig := q.Get("X-Amz-Signature"); sig != "" {
+		credential, signedHeaders, signature = q.Get("X-Amz-Credential"), q.Get("X-Amz-SignedHeaders"), sig
+		amzDate, payload = q.Get("X-Amz-Date"), s3UnsignedPayload
+		if q.Get("X-Amz-Algorithm") != s3Algorithm {
+			return fmt.Errorf("unexpected algorithm %q", q.Get("X-Amz-Algorithm"))
+		}
+		signedAt, err := time.Parse("20060102T150405Z", amzDate)
+		if err != nil {
+			return fmt.Errorf("bad X-Amz-Date: %v", err)
+		}
+		expires, err := strconv.Atoi(q.Get("X-Amz-Expires"))
+		if err != nil || expires <= 0 || expires > 7*24*3600 {
+			return fmt.Errorf("bad X-Amz-Expires %q", q.Get("X-Amz-Expires"))
+		}
+		if time.Now().After(signedAt.Add(time.Duration(expires) * time.Second)) {
+			return fmt.Errorf("request has expired")
+		}
+		q.Del("X-Amz-Signature")
+	} else {
+		auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), s3Algorithm+" ")
+		if !ok {
+			return fmt.Errorf("missing authorization")
+		}
+		for _, part := range strings.Split(auth, ", ") {
+			k, v, _ := strings.Cut(part, "=")
+			switch k {
+			case "Credential":
+				credential = v
+			case "SignedHeaders":
+				signedHeaders = v
+			case "Signature":
+				signature = v
+			}
+		}
+		amzDate, payload = r.Header.Get("X-Amz-Date"), r.Header.Get("X-Amz-Content-Sha256")
+	}
+
+	access, scope, _ := strings.Cut(credential, "/")
+	if access != f.accessKey {
+		return fmt.Errorf("unknown access key %q", access)
+	}
+	if len(amzDate) < 8 || scope != amzDate[:8]+"/"+f.region+"/s3/aws4_request" {
+		return fmt.Errorf("bad credential scope %q", scope)
+	}
+	var headers strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = strconv.FormatInt(r.ContentLength, 10)
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical := strings.Join([]string{
+		r.Method,
+		escapePath(r.URL.Path),
+		canonicalQuery(q),
+		headers.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+	if want := fakeSignature(f.secretKey, amzDate, scope, canonical); !hmac.Equal([]byte(want), []byte(signature)) {
+		return fmt.Errorf("SignatureDoesNotMatch")
+	}
+	return nil
+}
+
+func fakeSignature(secret, amzDate, scope, canonical string) string {
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+	key := []byte("AWS4" + secret)
+	for _, part := range strings.Split(scope, "/") {
+		key = mac(key, part)
+	}
+	return hex.EncodeToString(mac(key, stringToSign))
+}
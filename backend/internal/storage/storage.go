@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/config"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidTTL = errors.New("presign ttl must be positive")
+	// ErrObjectExists 预签名直传只能创建新对象，不能覆盖已有对象
+	ErrObjectExists = errors.New("object already exists")
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+}
+
+// Object 可随机读取的对象，用于 Range 读取
+type Object interface {
+	io.ReadSeekCloser
+	Info() ObjectInfo
+}
+
+// Backend 上传文件的存储后端，key 为不带前导 / 的相对路径，例如 videos/1/20250101/xxx.mp4
+type Backend interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (Object, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// URL 对象的公开访问地址，可能是以 / 开头的相对地址
+	URL(key string) string
+	// KeyFromURL 从 URL 反解出 key，不属于本后端的地址返回 false
+	KeyFromURL(rawURL string) (string, bool)
+	// PresignPut/PresignGet 生成客户端直传/直读的临时地址。
+	// 直传地址只能创建大小恰为 size 的新对象，不能覆盖已有 key，客户端需带上 PresignPutHeaders 返回的请求头
+	PresignPut(ctx context.Context, key string, contentType string, size int64, ttl time.Duration) (string, error)
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// List 遍历 prefix 下的对象，fn 返回错误时停止
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+}
+
+// PresignPutHeaders 使用直传地址时必须携带的请求头，Content-Length 由客户端按文件大小自动设置
+func PresignPutHeaders(contentType string) map[string]string {
+	h := map[string]string{"If-None-Match": "*"}
+	if contentType != "" {
+		h["Content-Type"] = contentType
+	}
+	return h
+}
+
+func New(cfg config.StorageConfig) (Backend, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.Local), nil
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
+
+// CleanKey 校验并规范化 key，拒绝目录穿越
+func CleanKey(key string) (string, error) {
+	key = strings.TrimLeft(strings.TrimSpace(key), "/")
+	if key == "" {
+		return "", errors.New("empty key")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", errors.New("invalid key")
+		}
+	}
+	return key, nil
+}
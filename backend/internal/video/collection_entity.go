@@ -0,0 +1,80 @@
+package video
+
+import "time"
+
+// Collection 收藏夹，visibility 只取 public / private
+type Collection struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	AccountID   uint      `gorm:"uniqueIndex:idx_collection_account_name;not null" json:"account_id"`
+	Name        string    `gorm:"type:varchar(64);uniqueIndex:idx_collection_account_name;not null" json:"name"`
+	Visibility  string    `gorm:"type:varchar(16);not null;default:private" json:"visibility"`
+	VideosCount int64     `gorm:"not null;default:0" json:"videos_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CollectionItem struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	CollectionID uint `gorm:"uniqueIndex:idx_collection_item;not null" json:"collection_id"`
+	VideoID      uint `gorm:"uniqueIndex:idx_collection_item;index;not null" json:"video_id"`
+	// 冗余收藏夹所有者，统计视频被多少人收藏
+	AccountID uint      `gorm:"index;not null" json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateCollectionRequest struct {
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+}
+
+type RenameCollectionRequest struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type SetCollectionVisibilityRequest struct {
+	ID         uint   `json:"id"`
+	Visibility string `json:"visibility"`
+}
+
+type DeleteCollectionRequest struct {
+	ID uint `json:"id"`
+}
+
+type CollectionVideoRequest struct {
+	CollectionID uint `json:"collection_id"`
+	VideoID      uint `json:"video_id"`
+}
+
+type ListCollectionsRequest struct {
+	// 为空时列出自己的收藏夹
+	AccountID uint `json:"account_id"`
+	Limit     int  `json:"limit"`
+	BeforeID  uint `json:"before_id"`
+}
+
+type ListCollectionsResponse struct {
+	Collections  []Collection `json:"collections"`
+	NextBeforeID uint         `json:"next_before_id"`
+	HasMore      bool         `json:"has_more"`
+}
+
+type ListCollectionVideosRequest struct {
+	CollectionID uint `json:"collection_id"`
+	Limit        int  `json:"limit"`
+	// 游标：上一页最后一条的 item_id，按加入时间倒序
+	BeforeItemID uint `json:"before_item_id"`
+}
+
+type CollectionVideo struct {
+	ItemID      uint   `json:"item_id"`
+	CollectedAt int64  `json:"collected_at"`
+	Video       *Video `json:"video"`
+}
+
+type ListCollectionVideosResponse struct {
+	Collection       *Collection       `json:"collection"`
+	Videos           []CollectionVideo `json:"videos"`
+	NextBeforeItemID uint              `json:"next_before_item_id"`
+	HasMore          bool              `json:"has_more"`
+}
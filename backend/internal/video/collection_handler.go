@@ -0,0 +1,186 @@
+package video
+
+import (
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+	service *CollectionService
+}
+
+func NewCollectionHandler(service *CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) Create(c *gin.Context) {
+	var req CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.Create(c.Request.Context(), accountID, req.Name, req.Visibility)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, collection)
+}
+
+func (h *CollectionHandler) Rename(c *gin.Context) {
+	var req RenameCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.Rename(c.Request.Context(), req.ID, accountID, req.Name)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, collection)
+}
+
+func (h *CollectionHandler) SetVisibility(c *gin.Context) {
+	var req SetCollectionVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.SetVisibility(c.Request.Context(), req.ID, accountID, req.Visibility)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, collection)
+}
+
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	var req DeleteCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), req.ID, accountID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "collection deleted"})
+}
+
+func (h *CollectionHandler) AddVideo(c *gin.Context) {
+	var req CollectionVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CollectionID == 0 || req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "collection_id and video_id are required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.AddVideo(c.Request.Context(), accountID, req.CollectionID, req.VideoID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "video added"})
+}
+
+func (h *CollectionHandler) RemoveVideo(c *gin.Context) {
+	var req CollectionVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CollectionID == 0 || req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "collection_id and video_id are required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.RemoveVideo(c.Request.Context(), accountID, req.CollectionID, req.VideoID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "video removed"})
+}
+
+func (h *CollectionHandler) List(c *gin.Context) {
+	var req ListCollectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	resp, err := h.service.List(c.Request.Context(), viewerAccountID, req)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h *CollectionHandler) ListVideos(c *gin.Context) {
+	var req ListCollectionVideosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CollectionID == 0 {
+		c.JSON(400, gin.H{"error": "collection_id is required"})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	resp, err := h.service.ListVideos(c.Request.Context(), viewerAccountID, req)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
@@ -0,0 +1,118 @@
+package video
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type CollectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+func (r *CollectionRepository) Create(ctx context.Context, c *Collection) error {
+	return r.db.WithContext(ctx).Create(c).Error
+}
+
+func (r *CollectionRepository) GetByID(ctx context.Context, id uint) (*Collection, error) {
+	var c Collection
+	if err := r.db.WithContext(ctx).First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CollectionRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Collection{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete 删除收藏夹及其条目，返回受影响的视频以便重算收藏数
+func (r *CollectionRepository) Delete(ctx context.Context, id uint) ([]uint, error) {
+	var videoIDs []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&CollectionItem{}).Where("collection_id = ?", id).Pluck("video_id", &videoIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", id).Delete(&CollectionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Collection{}, id).Error
+	})
+	return videoIDs, err
+}
+
+func (r *CollectionRepository) ListByAccount(ctx context.Context, accountID uint, publicOnly bool, beforeID uint, limit int) ([]Collection, error) {
+	var collections []Collection
+	query := r.db.WithContext(ctx).Where("account_id = ?", accountID)
+	if publicOnly {
+		query = query.Where("visibility = ?", VisibilityPublic)
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if err := query.Order("id desc").Limit(limit).Find(&collections).Error; err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// AddItem 加入视频并更新收藏夹计数，已存在时返回 false
+func (r *CollectionRepository) AddItem(ctx context.Context, item *CollectionItem) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			if isDupKey(err) {
+				return nil
+			}
+			return err
+		}
+		added = true
+		return tx.Model(&Collection{}).Where("id = ?", item.CollectionID).
+			UpdateColumn("videos_count", gorm.Expr("videos_count + 1")).Error
+	})
+	return added, err
+}
+
+// RemoveItem 移除视频并更新收藏夹计数，不存在时返回 false
+func (r *CollectionRepository) RemoveItem(ctx context.Context, collectionID, videoID uint) (bool, error) {
+	removed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("collection_id = ? AND video_id = ?", collectionID, videoID).Delete(&CollectionItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		removed = true
+		return tx.Model(&Collection{}).Where("id = ?", collectionID).
+			UpdateColumn("videos_count", gorm.Expr("GREATEST(videos_count - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+func (r *CollectionRepository) ListItems(ctx context.Context, collectionID uint, beforeItemID uint, limit int) ([]CollectionItem, error) {
+	var items []CollectionItem
+	query := r.db.WithContext(ctx).Where("collection_id = ?", collectionID)
+	if beforeItemID > 0 {
+		query = query.Where("id < ?", beforeItemID)
+	}
+	if err := query.Order("id desc").Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CountCollectors 统计收藏过该视频的账号数，同一账号放进多个收藏夹只算一次
+func (r *CollectionRepository) CountCollectors(ctx context.Context, videoID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&CollectionItem{}).
+		Where("video_id = ?", videoID).
+		Distinct("account_id").
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,276 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const maxCollectionNameLen = 64
+
+type CollectionService struct {
+	repo      *CollectionRepository
+	videoRepo *VideoRepository
+	videos    *VideoService
+	media     *MediaService
+	collectMQ *rabbitmq.CollectMQ
+}
+
+func NewCollectionService(repo *CollectionRepository, videoRepo *VideoRepository, videos *VideoService, media *MediaService, collectMQ *rabbitmq.CollectMQ) *CollectionService {
+	return &CollectionService{repo: repo, videoRepo: videoRepo, videos: videos, media: media, collectMQ: collectMQ}
+}
+
+func normalizeCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxCollectionNameLen {
+		return "", errors.New("name is too long")
+	}
+	return name, nil
+}
+
+func normalizeCollectionVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPublic, VisibilityPrivate:
+		return visibility, nil
+	default:
+		return "", errors.New("visibility must be public or private")
+	}
+}
+
+func collectionPageLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > 50 {
+		return 50
+	}
+	return limit
+}
+
+func (s *CollectionService) Create(ctx context.Context, accountID uint, name, visibility string) (*Collection, error) {
+	name, err := normalizeCollectionName(name)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err = normalizeCollectionVisibility(visibility)
+	if err != nil {
+		return nil, err
+	}
+	c := &Collection{AccountID: accountID, Name: name, Visibility: visibility}
+	if err := s.repo.Create(ctx, c); err != nil {
+		if isDupKey(err) {
+			return nil, errors.New("collection name already exists")
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// getOwned 读取收藏夹并校验归属，不属于自己的按不存在处理
+func (s *CollectionService) getOwned(ctx context.Context, id, accountID uint) (*Collection, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("collection not found")
+		}
+		return nil, err
+	}
+	if c.AccountID != accountID {
+		return nil, errors.New("collection not found")
+	}
+	return c, nil
+}
+
+func (s *CollectionService) Rename(ctx context.Context, id, accountID uint, name string) (*Collection, error) {
+	c, err := s.getOwned(ctx, id, accountID)
+	if err != nil {
+		return nil, err
+	}
+	name, err = normalizeCollectionName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, id, map[string]interface{}{"name": name}); err != nil {
+		if isDupKey(err) {
+			return nil, errors.New("collection name already exists")
+		}
+		return nil, err
+	}
+	c.Name = name
+	return c, nil
+}
+
+func (s *CollectionService) SetVisibility(ctx context.Context, id, accountID uint, visibility string) (*Collection, error) {
+	c, err := s.getOwned(ctx, id, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if visibility == "" {
+		return nil, errors.New("visibility is required")
+	}
+	visibility, err = normalizeCollectionVisibility(visibility)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, id, map[string]interface{}{"visibility": visibility}); err != nil {
+		return nil, err
+	}
+	c.Visibility = visibility
+	return c, nil
+}
+
+func (s *CollectionService) Delete(ctx context.Context, id, accountID uint) error {
+	if _, err := s.getOwned(ctx, id, accountID); err != nil {
+		return err
+	}
+	videoIDs, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, videoID := range videoIDs {
+		s.refreshCollectCount(ctx, accountID, videoID, false)
+	}
+	return nil
+}
+
+func (s *CollectionService) AddVideo(ctx context.Context, accountID, collectionID, videoID uint) error {
+	if _, err := s.getOwned(ctx, collectionID, accountID); err != nil {
+		return err
+	}
+	// 只能收藏自己看得到的视频
+	if _, err := s.videos.GetDetail(ctx, videoID, accountID); err != nil {
+		return err
+	}
+	added, err := s.repo.AddItem(ctx, &CollectionItem{CollectionID: collectionID, VideoID: videoID, AccountID: accountID})
+	if err != nil {
+		return err
+	}
+	if !added {
+		return errors.New("video already in collection")
+	}
+	s.refreshCollectCount(ctx, accountID, videoID, true)
+	return nil
+}
+
+func (s *CollectionService) RemoveVideo(ctx context.Context, accountID, collectionID, videoID uint) error {
+	if _, err := s.getOwned(ctx, collectionID, accountID); err != nil {
+		return err
+	}
+	removed, err := s.repo.RemoveItem(ctx, collectionID, videoID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("video not in collection")
+	}
+	s.refreshCollectCount(ctx, accountID, videoID, false)
+	return nil
+}
+
+// refreshCollectCount 通过 MQ 异步重算视频收藏数，发送失败时直接重算
+func (s *CollectionService) refreshCollectCount(ctx context.Context, accountID, videoID uint, collected bool) {
+	if s.collectMQ != nil {
+		publish := s.collectMQ.Uncollect
+		if collected {
+			publish = s.collectMQ.Collect
+		}
+		if err := publish(ctx, accountID, videoID); err == nil {
+			return
+		}
+	}
+	_ = RefreshCollectCount(ctx, s.repo, s.videoRepo, videoID)
+}
+
+// RefreshCollectCount 按收藏条目重算视频收藏数，重复执行结果一致
+func RefreshCollectCount(ctx context.Context, repo *CollectionRepository, videoRepo *VideoRepository, videoID uint) error {
+	count, err := repo.CountCollectors(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	return videoRepo.SetCollectCount(ctx, videoID, count)
+}
+
+// List 列出账号的收藏夹，别人只能看到公开的
+func (s *CollectionService) List(ctx context.Context, viewerAccountID uint, req ListCollectionsRequest) (*ListCollectionsResponse, error) {
+	ownerID := req.AccountID
+	if ownerID == 0 {
+		ownerID = viewerAccountID
+	}
+	if ownerID == 0 {
+		return nil, errors.New("account_id is required")
+	}
+	limit := collectionPageLimit(req.Limit)
+	collections, err := s.repo.ListByAccount(ctx, ownerID, ownerID != viewerAccountID, req.BeforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListCollectionsResponse{Collections: collections}
+	if len(collections) > limit {
+		resp.Collections = collections[:limit]
+		resp.HasMore = true
+	}
+	if n := len(resp.Collections); n > 0 {
+		resp.NextBeforeID = resp.Collections[n-1].ID
+	}
+	return resp, nil
+}
+
+func (s *CollectionService) ListVideos(ctx context.Context, viewerAccountID uint, req ListCollectionVideosRequest) (*ListCollectionVideosResponse, error) {
+	c, err := s.repo.GetByID(ctx, req.CollectionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("collection not found")
+		}
+		return nil, err
+	}
+	if c.Visibility != VisibilityPublic && c.AccountID != viewerAccountID {
+		return nil, errors.New("collection not found")
+	}
+
+	limit := collectionPageLimit(req.Limit)
+	items, err := s.repo.ListItems(ctx, c.ID, req.BeforeItemID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListCollectionVideosResponse{Collection: c, Videos: []CollectionVideo{}}
+	if len(items) > limit {
+		items = items[:limit]
+		resp.HasMore = true
+	}
+	if len(items) == 0 {
+		return resp, nil
+	}
+	resp.NextBeforeItemID = items[len(items)-1].ID
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.VideoID)
+	}
+	videos, err := s.videoRepo.ListVisibleByIDs(ctx, ids, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Video, len(videos))
+	for i := range videos {
+		byID[videos[i].ID] = &videos[i]
+	}
+	// 已删除或对当前观众不可见的视频不返回
+	for _, item := range items {
+		v, ok := byID[item.VideoID]
+		if !ok {
+			continue
+		}
+		s.media.FillURLs(v)
+		resp.Videos = append(resp.Videos, CollectionVideo{ItemID: item.ID, CollectedAt: item.CreatedAt.Unix(), Video: v})
+	}
+	return resp, nil
+}
@@ -3,12 +3,15 @@ package video
 import "time"
 
 type Comment struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"index" json:"username"`
-	VideoID   uint      `gorm:"index" json:"video_id"`
-	AuthorID  uint      `gorm:"index" json:"author_id"`
-	Content   string    `gorm:"type:text" json:"content"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Username string `gorm:"index" json:"username"`
+	VideoID  uint   `gorm:"index" json:"video_id"`
+	AuthorID uint   `gorm:"index" json:"author_id"`
+	Content  string `gorm:"type:text" json:"content"`
+	Hidden   bool   `gorm:"not null;default:false" json:"hidden,omitempty"`
+	// 隐藏原因，见 HiddenByReport/HiddenByAdmin
+	HiddenReason string    `gorm:"type:varchar(16);not null;default:''" json:"hidden_reason,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
 type PublishCommentRequest struct {
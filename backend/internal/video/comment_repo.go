@@ -2,6 +2,7 @@ package video
 
 import (
 	"context"
+	"feedsystem_video_go/internal/account"
 
 	"gorm.io/gorm"
 )
@@ -24,7 +25,10 @@ func (r *CommentRepository) DeleteComment(ctx context.Context, comment *Comment)
 
 func (r *CommentRepository) GetAllComments(ctx context.Context, videoID uint) ([]Comment, error) {
 	var comments []Comment
-	err := r.db.WithContext(ctx).Where("video_id = ?", videoID).Find(&comments).Error
+	err := r.db.WithContext(ctx).
+		Where("video_id = ? AND hidden = ?", videoID, false).
+		Where("author_id NOT IN (?)", account.RestrictedIDs(r.db)).
+		Find(&comments).Error
 	return comments, err
 }
 
@@ -49,3 +53,21 @@ func (r *CommentRepository) GetByID(ctx context.Context, id uint) (*Comment, err
 	}
 	return &comment, nil
 }
+
+// HideIfVisible 只隐藏当前可见的评论，已隐藏的保留原来的原因
+func (r *CommentRepository) HideIfVisible(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&Comment{}).
+		Where("id = ? AND hidden = ?", id, false).
+		Updates(map[string]interface{}{"hidden": true, "hidden_reason": reason}).Error
+}
+
+// UnhideIfReason 只恢复因 reason 被隐藏的评论
+func (r *CommentRepository) UnhideIfReason(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&Comment{}).
+		Where("id = ? AND hidden = ? AND hidden_reason = ?", id, true, reason).
+		Updates(map[string]interface{}{"hidden": false, "hidden_reason": ""}).Error
+}
+
+func (r *CommentRepository) DeleteByVideoID(ctx context.Context, videoID uint) error {
+	return r.db.WithContext(ctx).Where("video_id = ?", videoID).Delete(&Comment{}).Error
+}
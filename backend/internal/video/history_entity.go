@@ -0,0 +1,46 @@
+package video
+
+import "time"
+
+// WatchHistory 每个账号每个视频一条，记录最近一次观看时间和播放位置
+type WatchHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	AccountID  uint      `gorm:"uniqueIndex:idx_history_account_video;index:idx_history_account_time,priority:1;not null" json:"account_id"`
+	VideoID    uint      `gorm:"uniqueIndex:idx_history_account_video;not null" json:"video_id"`
+	PositionMs int64     `gorm:"not null;default:0" json:"position_ms"`
+	WatchedAt  time.Time `gorm:"type:datetime(3);index:idx_history_account_time,priority:2;not null" json:"watched_at"`
+}
+
+// WatchHistoryClear 清除水位线：删除或清空时写入，早于 ClearedAt 的观看消息（MQ 中排队的旧事件）不再生效。
+// VideoID 为 0 表示清空整个账号的历史
+type WatchHistoryClear struct {
+	AccountID uint      `gorm:"primaryKey;autoIncrement:false" json:"account_id"`
+	VideoID   uint      `gorm:"primaryKey;autoIncrement:false" json:"video_id"`
+	ClearedAt time.Time `gorm:"type:datetime(3);not null" json:"cleared_at"`
+}
+
+type HistoryItem struct {
+	Video *Video `json:"video"`
+	// 续播位置(毫秒)，看完的视频为 0
+	PositionMs int64 `json:"position_ms"`
+	// 最近观看时间（unix 毫秒）
+	WatchedAt int64 `json:"watched_at"`
+}
+
+type ListHistoryRequest struct {
+	Limit int `json:"limit"`
+	// 游标：上一页最后一条的 watched_at 和 video_id，首页不传
+	BeforeTime    int64 `json:"before_time"`
+	BeforeVideoID uint  `json:"before_video_id"`
+}
+
+type ListHistoryResponse struct {
+	Items             []HistoryItem `json:"items"`
+	NextBeforeTime    int64         `json:"next_before_time"`
+	NextBeforeVideoID uint          `json:"next_before_video_id"`
+	HasMore           bool          `json:"has_more"`
+}
+
+type DeleteHistoryRequest struct {
+	VideoID uint `json:"video_id"`
+}
@@ -0,0 +1,69 @@
+package video
+
+import (
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HistoryHandler struct {
+	service *HistoryService
+}
+
+func NewHistoryHandler(service *HistoryService) *HistoryHandler {
+	return &HistoryHandler{service: service}
+}
+
+func (hh *HistoryHandler) List(c *gin.Context) {
+	var req ListHistoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := hh.service.List(c.Request.Context(), accountID, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (hh *HistoryHandler) Delete(c *gin.Context) {
+	var req DeleteHistoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "video_id is required"})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := hh.service.Delete(c.Request.Context(), accountID, req.VideoID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "history deleted"})
+}
+
+func (hh *HistoryHandler) Clear(c *gin.Context) {
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := hh.service.Clear(c.Request.Context(), accountID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "history cleared"})
+}
@@ -0,0 +1,104 @@
+package video
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// notCleared 过滤掉早于清除水位线的记录：Upsert 检查与清除并发时仍可能有旧消息落库，读取时一并排除
+func notCleared(db *gorm.DB) *gorm.DB {
+	return db.Where("NOT EXISTS (SELECT 1 FROM watch_history_clears c WHERE c.account_id = watch_histories.account_id AND c.video_id IN (0, watch_histories.video_id) AND c.cleared_at >= watch_histories.watched_at)")
+}
+
+// Upsert 写入观看记录；消息乱序时只保留观看时间更新的那条，早于删除/清空的消息直接丢弃
+func (r *HistoryRepository) Upsert(ctx context.Context, h *WatchHistory) error {
+	var cleared int64
+	if err := r.db.WithContext(ctx).Model(&WatchHistoryClear{}).
+		Where("account_id = ? AND video_id IN ? AND cleared_at >= ?", h.AccountID, []uint{0, h.VideoID}, h.WatchedAt).
+		Count(&cleared).Error; err != nil {
+		return err
+	}
+	if cleared > 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}, {Name: "video_id"}},
+		// MySQL 按顺序赋值，position_ms 必须在 watched_at 之前比较
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "position_ms"}, Value: gorm.Expr("IF(VALUES(watched_at) >= watched_at, VALUES(position_ms), position_ms)")},
+			{Column: clause.Column{Name: "watched_at"}, Value: gorm.Expr("GREATEST(watched_at, VALUES(watched_at))")},
+		},
+	}).Create(h).Error
+}
+
+// ListByAccount 按观看时间倒序分页，before 为零值表示第一页
+func (r *HistoryRepository) ListByAccount(ctx context.Context, accountID uint, before time.Time, beforeVideoID uint, limit int) ([]WatchHistory, error) {
+	var items []WatchHistory
+	query := r.db.WithContext(ctx).Scopes(notCleared).Where("account_id = ?", accountID)
+	if !before.IsZero() {
+		query = query.Where("watched_at < ? OR (watched_at = ? AND video_id < ?)", before, before, beforeVideoID)
+	}
+	if err := query.Order("watched_at desc, video_id desc").Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *HistoryRepository) GetPositions(ctx context.Context, accountID uint, videoIDs []uint) (map[uint]int64, error) {
+	positions := make(map[uint]int64)
+	if accountID == 0 || len(videoIDs) == 0 {
+		return positions, nil
+	}
+	var items []WatchHistory
+	if err := r.db.WithContext(ctx).Scopes(notCleared).
+		Select("video_id", "position_ms").
+		Where("account_id = ? AND video_id IN ?", accountID, videoIDs).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range items {
+		positions[h.VideoID] = h.PositionMs
+	}
+	return positions, nil
+}
+
+// markCleared 记录清除水位线，只会向后推进
+func markCleared(tx *gorm.DB, accountID, videoID uint, at time.Time) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "video_id"}},
+		DoUpdates: clause.Set{{Column: clause.Column{Name: "cleared_at"}, Value: gorm.Expr("GREATEST(cleared_at, VALUES(cleared_at))")}},
+	}).Create(&WatchHistoryClear{AccountID: accountID, VideoID: videoID, ClearedAt: at}).Error
+}
+
+func (r *HistoryRepository) Delete(ctx context.Context, accountID, videoID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := markCleared(tx, accountID, videoID, time.Now()); err != nil {
+			return err
+		}
+		return tx.Where("account_id = ? AND video_id = ?", accountID, videoID).Delete(&WatchHistory{}).Error
+	})
+}
+
+func (r *HistoryRepository) Clear(ctx context.Context, accountID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := markCleared(tx, accountID, 0, time.Now()); err != nil {
+			return err
+		}
+		// 单条删除的水位线已被整体水位线覆盖
+		if err := tx.Where("account_id = ? AND video_id <> 0", accountID).Delete(&WatchHistoryClear{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("account_id = ?", accountID).Delete(&WatchHistory{}).Error
+	})
+}
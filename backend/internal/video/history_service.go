@@ -0,0 +1,260 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// Redis 中每个账号保留的最近观看条数
+	historyRecentSize = 100
+	historyCacheTTL   = 7 * 24 * time.Hour
+)
+
+type HistoryService struct {
+	repo      *HistoryRepository
+	videoRepo *VideoRepository
+	media     *MediaService
+	cache     *rediscache.Client
+	historyMQ *rabbitmq.HistoryMQ
+}
+
+func NewHistoryService(repo *HistoryRepository, videoRepo *VideoRepository, media *MediaService, cache *rediscache.Client, historyMQ *rabbitmq.HistoryMQ) *HistoryService {
+	return &HistoryService{repo: repo, videoRepo: videoRepo, media: media, cache: cache, historyMQ: historyMQ}
+}
+
+func historyRecentKey(accountID uint) string {
+	return fmt.Sprintf("history:recent:%d", accountID)
+}
+
+func historyPositionKey(accountID uint) string {
+	return fmt.Sprintf("history:pos:%d", accountID)
+}
+
+// Record 记录一次观看：最近记录立即写入 Redis，完整历史经 MQ 异步落库
+func (s *HistoryService) Record(ctx context.Context, accountID, videoID uint, positionMs int64) error {
+	if accountID == 0 || videoID == 0 {
+		return errors.New("account_id and video_id are required")
+	}
+	if positionMs < 0 {
+		positionMs = 0
+	}
+	now := time.Now()
+
+	if s.cache != nil {
+		recentKey := historyRecentKey(accountID)
+		posKey := historyPositionKey(accountID)
+		member := strconv.FormatUint(uint64(videoID), 10)
+		if err := s.cache.ZAdd(ctx, recentKey, member, float64(now.UnixMilli())); err == nil {
+			_ = s.cache.HSet(ctx, posKey, member, strconv.FormatInt(positionMs, 10))
+			// 超出条数的旧记录只保留在 MySQL
+			if removed, err := s.cache.ZRemRangeByRank(ctx, recentKey, 0, -historyRecentSize-1); err == nil && len(removed) > 0 {
+				_ = s.cache.HDel(ctx, posKey, removed...)
+			}
+			_ = s.cache.Expire(ctx, recentKey, historyCacheTTL)
+			_ = s.cache.Expire(ctx, posKey, historyCacheTTL)
+		}
+	}
+
+	if s.historyMQ != nil {
+		if err := s.historyMQ.Watch(ctx, accountID, videoID, positionMs, now); err == nil {
+			return nil
+		}
+	}
+	// Fallback: MQ 不可用时直接写库
+	return s.repo.Upsert(ctx, &WatchHistory{AccountID: accountID, VideoID: videoID, PositionMs: positionMs, WatchedAt: now})
+}
+
+// recent 读取 Redis 中的最近观看记录，Redis 不可用时返回空
+func (s *HistoryService) recent(ctx context.Context, accountID uint) map[uint]WatchHistory {
+	out := make(map[uint]WatchHistory)
+	if s.cache == nil {
+		return out
+	}
+	members, err := s.cache.ZRevRangeWithScores(ctx, historyRecentKey(accountID), 0, -1)
+	if err != nil || len(members) == 0 {
+		return out
+	}
+	fields := make([]string, 0, len(members))
+	for _, m := range members {
+		fields = append(fields, m.Member)
+	}
+	positions, err := s.cache.HMGet(ctx, historyPositionKey(accountID), fields...)
+	if err != nil {
+		return out
+	}
+	for _, m := range members {
+		id, err := strconv.ParseUint(m.Member, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		pos, _ := strconv.ParseInt(positions[m.Member], 10, 64)
+		out[uint(id)] = WatchHistory{
+			AccountID:  accountID,
+			VideoID:    uint(id),
+			PositionMs: pos,
+			WatchedAt:  time.UnixMilli(int64(m.Score)),
+		}
+	}
+	return out
+}
+
+// List 按观看时间倒序分页；Redis 中的最近记录比 MySQL 新，两边合并后以 Redis 为准
+func (s *HistoryService) List(ctx context.Context, accountID uint, req ListHistoryRequest) (*ListHistoryResponse, error) {
+	if accountID == 0 {
+		return nil, errors.New("account_id is required")
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	var before time.Time
+	if req.BeforeTime > 0 {
+		before = time.UnixMilli(req.BeforeTime)
+	}
+	isBefore := func(h WatchHistory) bool {
+		if before.IsZero() {
+			return true
+		}
+		ms := h.WatchedAt.UnixMilli()
+		return ms < req.BeforeTime || (ms == req.BeforeTime && h.VideoID < req.BeforeVideoID)
+	}
+
+	recent := s.recent(ctx, accountID)
+	// 多取 len(recent) 条，抵消被 Redis 记录覆盖的行
+	rows, err := s.repo.ListByAccount(ctx, accountID, before, req.BeforeVideoID, limit+len(recent)+1)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]WatchHistory, 0, len(rows)+len(recent))
+	for _, h := range recent {
+		if isBefore(h) {
+			entries = append(entries, h)
+		}
+	}
+	for _, h := range rows {
+		if _, ok := recent[h.VideoID]; !ok {
+			entries = append(entries, h)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].WatchedAt.UnixMilli(), entries[j].WatchedAt.UnixMilli()
+		if a != b {
+			return a > b
+		}
+		return entries[i].VideoID > entries[j].VideoID
+	})
+
+	resp := &ListHistoryResponse{Items: []HistoryItem{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		resp.HasMore = true
+	}
+	if len(entries) == 0 {
+		return resp, nil
+	}
+	last := entries[len(entries)-1]
+	resp.NextBeforeTime = last.WatchedAt.UnixMilli()
+	resp.NextBeforeVideoID = last.VideoID
+
+	ids := make([]uint, 0, len(entries))
+	for _, h := range entries {
+		ids = append(ids, h.VideoID)
+	}
+	videos, err := s.videoRepo.ListVisibleByIDs(ctx, ids, accountID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Video, len(videos))
+	for i := range videos {
+		byID[videos[i].ID] = &videos[i]
+	}
+	// 已删除或不再可见的视频不返回，但游标照常前进
+	for _, h := range entries {
+		v, ok := byID[h.VideoID]
+		if !ok {
+			continue
+		}
+		s.media.FillURLs(v)
+		resp.Items = append(resp.Items, HistoryItem{Video: v, PositionMs: h.PositionMs, WatchedAt: h.WatchedAt.UnixMilli()})
+	}
+	return resp, nil
+}
+
+// ResumePositions 批量查询续播位置，只返回有观看记录的视频
+func (s *HistoryService) ResumePositions(ctx context.Context, accountID uint, videoIDs []uint) (map[uint]int64, error) {
+	positions := make(map[uint]int64)
+	if accountID == 0 || len(videoIDs) == 0 {
+		return positions, nil
+	}
+	missing := videoIDs
+	if s.cache != nil {
+		fields := make([]string, len(videoIDs))
+		for i, id := range videoIDs {
+			fields[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		if cached, err := s.cache.HMGet(ctx, historyPositionKey(accountID), fields...); err == nil {
+			missing = make([]uint, 0, len(videoIDs))
+			for i, id := range videoIDs {
+				raw, ok := cached[fields[i]]
+				if !ok {
+					missing = append(missing, id)
+					continue
+				}
+				pos, _ := strconv.ParseInt(raw, 10, 64)
+				positions[id] = pos
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return positions, nil
+	}
+	stored, err := s.repo.GetPositions(ctx, accountID, missing)
+	if err != nil {
+		return nil, err
+	}
+	for id, pos := range stored {
+		positions[id] = pos
+	}
+	return positions, nil
+}
+
+func (s *HistoryService) ResumePosition(ctx context.Context, accountID, videoID uint) (int64, error) {
+	positions, err := s.ResumePositions(ctx, accountID, []uint{videoID})
+	if err != nil {
+		return 0, err
+	}
+	return positions[videoID], nil
+}
+
+func (s *HistoryService) Delete(ctx context.Context, accountID, videoID uint) error {
+	if accountID == 0 || videoID == 0 {
+		return errors.New("account_id and video_id are required")
+	}
+	if s.cache != nil {
+		member := strconv.FormatUint(uint64(videoID), 10)
+		_ = s.cache.ZRem(ctx, historyRecentKey(accountID), member)
+		_ = s.cache.HDel(ctx, historyPositionKey(accountID), member)
+	}
+	return s.repo.Delete(ctx, accountID, videoID)
+}
+
+func (s *HistoryService) Clear(ctx context.Context, accountID uint) error {
+	if accountID == 0 {
+		return errors.New("account_id is required")
+	}
+	if s.cache != nil {
+		_ = s.cache.Del(ctx, historyRecentKey(accountID))
+		_ = s.cache.Del(ctx, historyPositionKey(accountID))
+	}
+	return s.repo.Clear(ctx, accountID)
+}
@@ -0,0 +1,115 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"feedsystem_video_go/internal/media"
+
+	"gorm.io/gorm"
+)
+
+// HLSPackager 把已上传的 MP4 重新封装为 HLS（fMP4 分片 + m3u8），由 worker 异步执行。
+// 打包结果记录在 MediaObject 上，并同步到所有引用该文件的视频
+type HLSPackager struct {
+	media   *MediaService
+	videos  *VideoRepository
+	segment time.Duration
+}
+
+func NewHLSPackager(media *MediaService, videos *VideoRepository, segment time.Duration) *HLSPackager {
+	if segment <= 0 {
+		segment = 6 * time.Second
+	}
+	return &HLSPackager{media: media, videos: videos, segment: segment}
+}
+
+// Package 打包 playKey 对应的视频文件，返回 HLS 状态发生变化的视频 id。
+// 文件不支持（非 H.264/AAC、内容损坏）时标记为 failed 且不返回错误，存储错误返回给调用方重试
+func (p *HLSPackager) Package(ctx context.Context, playKey string) ([]uint, error) {
+	obj, err := p.media.repo.GetByKey(ctx, playKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if obj.Kind != MediaKindVideo {
+		return nil, nil
+	}
+	if obj.HLSStatus == HLSStatusReady || obj.HLSStatus == HLSStatusFailed {
+		// 已有结果，只需同步到新引用的视频
+		return p.sync(ctx, playKey, obj.HLSStatus, obj.HLSKey)
+	}
+	if err := p.media.repo.SetHLS(ctx, obj.ID, HLSStatusProcessing, ""); err != nil {
+		return nil, err
+	}
+
+	err = p.remux(ctx, playKey)
+	switch {
+	case err == nil:
+		hlsKey := media.HLSKey(playKey, media.HLSPlaylistName)
+		if err := p.media.repo.SetHLS(ctx, obj.ID, HLSStatusReady, hlsKey); err != nil {
+			return nil, err
+		}
+		return p.sync(ctx, playKey, HLSStatusReady, hlsKey)
+	case errors.Is(err, media.ErrInvalidMP4), errors.Is(err, media.ErrHLSUnsupported):
+		log.Printf("hls packager: skip %s: %v", playKey, err)
+		if delErr := p.media.deleteHLS(context.Background(), playKey); delErr != nil {
+			log.Printf("hls packager: failed to remove partial output of %s: %v", playKey, delErr)
+		}
+		if err := p.media.repo.SetHLS(ctx, obj.ID, HLSStatusFailed, ""); err != nil {
+			return nil, err
+		}
+		return p.sync(ctx, playKey, HLSStatusFailed, "")
+	default:
+		_ = p.media.repo.SetHLS(context.Background(), obj.ID, HLSStatusPending, "")
+		return nil, err
+	}
+}
+
+func (p *HLSPackager) sync(ctx context.Context, playKey, status, hlsKey string) ([]uint, error) {
+	if err := p.videos.SetHLSByPlayKey(ctx, playKey, status, hlsKey); err != nil {
+		return nil, err
+	}
+	return p.videos.ListIDsByPlayKey(ctx, playKey)
+}
+
+// remux 先把源文件下载到临时文件，便于按样本随机读取
+func (p *HLSPackager) remux(ctx context.Context, playKey string) error {
+	src, err := p.media.store.Open(ctx, playKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "hls-*.mp4")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, src)
+	if err != nil {
+		return err
+	}
+	return media.PackageHLS(tmp, size, p.segment, func(name string, data []byte) error {
+		return p.media.store.Put(ctx, media.HLSKey(playKey, name), bytes.NewReader(data), int64(len(data)), hlsContentType(name))
+	})
+}
+
+func hlsContentType(name string) string {
+	switch path.Ext(name) {
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".m4s":
+		return "video/iso.segment"
+	default:
+		return "video/mp4"
+	}
+}
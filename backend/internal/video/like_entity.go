@@ -5,10 +5,33 @@ import "time"
 type Like struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	VideoID   uint      `gorm:"uniqueIndex:idx_like_video_account;not null" json:"video_id"`
-	AccountID uint      `gorm:"uniqueIndex:idx_like_video_account;not null" json:"account_id"`
-	CreatedAt time.Time `json:"created_at"`
+	AccountID uint      `gorm:"uniqueIndex:idx_like_video_account;index:idx_like_account_time,priority:1;not null" json:"account_id"`
+	CreatedAt time.Time `gorm:"type:datetime(3);index:idx_like_account_time,priority:2" json:"created_at"`
 }
 
 type LikeRequest struct {
 	VideoID uint `json:"video_id"`
 }
+
+type ListLikedVideosRequest struct {
+	// 要查看的账号，不传则为自己
+	AccountID uint `json:"account_id"`
+	Limit     int  `json:"limit"`
+	// 游标：上一页最后一条的点赞时间（unix 毫秒）和 like_id，首页不传
+	BeforeTime   int64 `json:"before_time"`
+	BeforeLikeID uint  `json:"before_like_id"`
+}
+
+type LikedVideo struct {
+	LikeID uint `json:"like_id"`
+	// 点赞时间（unix 毫秒）
+	LikedAt int64  `json:"liked_at"`
+	Video   *Video `json:"video"`
+}
+
+type ListLikedVideosResponse struct {
+	Videos           []LikedVideo `json:"videos"`
+	NextBeforeTime   int64        `json:"next_before_time"`
+	NextBeforeLikeID uint         `json:"next_before_like_id"`
+	HasMore          bool         `json:"has_more"`
+}
@@ -1,17 +1,20 @@
 package video
 
 import (
+	"errors"
 	"feedsystem_video_go/internal/middleware/jwt"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type LikeHandler struct {
 	service *LikeService
+	media   *MediaService
 }
 
-func NewLikeHandler(service *LikeService) *LikeHandler {
-	return &LikeHandler{service: service}
+func NewLikeHandler(service *LikeService, media *MediaService) *LikeHandler {
+	return &LikeHandler{service: service, media: media}
 }
 
 func (lh *LikeHandler) Like(c *gin.Context) {
@@ -94,17 +97,50 @@ func (lh *LikeHandler) IsLiked(c *gin.Context) {
 	c.JSON(200, gin.H{"is_liked": isLiked})
 }
 
+func (lh *LikeHandler) ListLikedVideos(c *gin.Context) {
+	var req ListLikedVideosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	lh.listLikedVideos(c, viewerAccountID, req)
+}
+
 func (lh *LikeHandler) ListMyLikedVideos(c *gin.Context) {
+	var req ListLikedVideosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 	accountID, err := jwt.GetAccountID(c)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	req.AccountID = accountID
+	lh.listLikedVideos(c, accountID, req)
+}
 
-	videos, err := lh.service.ListLikedVideos(c.Request.Context(), accountID)
+func (lh *LikeHandler) listLikedVideos(c *gin.Context, viewerAccountID uint, req ListLikedVideosRequest) {
+	resp, err := lh.service.ListLikedVideos(c.Request.Context(), viewerAccountID, req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		if errors.Is(err, ErrLikesPrivate) {
+			c.JSON(403, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "account not found"})
+			return
+		}
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(200, videos)
+	for i := range resp.Videos {
+		lh.media.FillURLs(resp.Videos[i].Video)
+	}
+	c.JSON(200, resp)
 }
@@ -3,6 +3,8 @@ package video
 import (
 	"context"
 	"errors"
+	"feedsystem_video_go/internal/account"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
@@ -82,19 +84,35 @@ func (r *LikeRepository) BatchGetLiked(ctx context.Context, videoIDs []uint, acc
 	return likeMap, nil
 }
 
-func (r *LikeRepository) ListLikedVideos(ctx context.Context, accountID uint) ([]Video, error) {
-	var videos []Video
+// ListLikes 按点赞时间倒序分页，只保留对 viewer 可见的视频（规则见 ViewableBy），before 为零值表示第一页
+func (r *LikeRepository) ListLikes(ctx context.Context, accountID, viewerAccountID uint, before time.Time, beforeID uint, limit int) ([]Like, error) {
+	var likes []Like
 	if accountID == 0 {
-		return videos, nil
+		return likes, nil
 	}
-	err := r.db.WithContext(ctx).
-		Model(&Video{}).
-		Joins("JOIN likes ON likes.video_id = videos.id").
+	query := r.db.WithContext(ctx).
+		Model(&Like{}).
+		Select("likes.*").
+		Joins("JOIN videos ON videos.id = likes.video_id").
+		Scopes(Listed).
 		Where("likes.account_id = ?", accountID).
-		Order("likes.created_at desc").
-		Find(&videos).Error
-	if err != nil {
+		Scopes(ViewableBy(viewerAccountID))
+	if !before.IsZero() {
+		query = query.Where("likes.created_at < ? OR (likes.created_at = ? AND likes.id < ?)", before, before, beforeID)
+	}
+	if err := query.Order("likes.created_at desc, likes.id desc").Limit(limit).Find(&likes).Error; err != nil {
 		return nil, err
 	}
-	return videos, nil
+	return likes, nil
+}
+
+// DeleteByVideoID 删除视频的全部点赞，并在同一事务中扣减作者的获赞数，重复执行不会重复扣减
+func (r *LikeRepository) DeleteByVideoID(ctx context.Context, videoID, authorID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("video_id = ?", videoID).Delete(&Like{})
+		if result.Error != nil {
+			return result.Error
+		}
+		return account.ChangeCounter(tx, authorID, account.CounterLikesReceived, -result.RowsAffected)
+	})
 }
@@ -3,6 +3,7 @@ package video
 import (
 	"context"
 	"errors"
+	"feedsystem_video_go/internal/account"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
 	"time"
@@ -17,10 +18,13 @@ type LikeService struct {
 	cache        *rediscache.Client
 	likeMQ       *rabbitmq.LikeMQ
 	popularityMQ *rabbitmq.PopularityMQ
+	accounts     *account.AccountRepository
 }
 
-func NewLikeService(repo *LikeRepository, videoRepo *VideoRepository, cache *rediscache.Client, likeMQ *rabbitmq.LikeMQ, popularityMQ *rabbitmq.PopularityMQ) *LikeService {
-	return &LikeService{repo: repo, VideoRepo: videoRepo, cache: cache, likeMQ: likeMQ, popularityMQ: popularityMQ}
+var ErrLikesPrivate = errors.New("liked videos are private")
+
+func NewLikeService(repo *LikeRepository, videoRepo *VideoRepository, cache *rediscache.Client, likeMQ *rabbitmq.LikeMQ, popularityMQ *rabbitmq.PopularityMQ, accounts *account.AccountRepository) *LikeService {
+	return &LikeService{repo: repo, VideoRepo: videoRepo, cache: cache, likeMQ: likeMQ, popularityMQ: popularityMQ, accounts: accounts}
 }
 
 func isDupKey(err error) bool {
@@ -90,6 +94,9 @@ func (s *LikeService) Like(ctx context.Context, like *Like) error {
 				UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error; err != nil {
 				return err
 			}
+			if err := changeAuthorLikesReceived(tx, like.VideoID, 1); err != nil {
+				return err
+			}
 			return tx.Model(&Video{}).Where("id = ?", like.VideoID).
 				UpdateColumn("popularity", gorm.Expr("popularity + 1")).Error
 		})
@@ -162,6 +169,9 @@ func (s *LikeService) Unlike(ctx context.Context, like *Like) error {
 				UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count - 1, 0)")).Error; err != nil {
 				return err
 			}
+			if err := changeAuthorLikesReceived(tx, like.VideoID, -1); err != nil {
+				return err
+			}
 			return tx.Model(&Video{}).Where("id = ?", like.VideoID).
 				UpdateColumn("popularity", gorm.Expr("GREATEST(popularity - 1, 0)")).Error
 		})
@@ -181,6 +191,70 @@ func (s *LikeService) IsLiked(ctx context.Context, videoID, accountID uint) (boo
 	return s.repo.IsLiked(ctx, videoID, accountID)
 }
 
-func (s *LikeService) ListLikedVideos(ctx context.Context, accountID uint) ([]Video, error) {
-	return s.repo.ListLikedVideos(ctx, accountID)
+// ListLikedVideos 分页读取 accountID 的点赞列表；查看他人时需对方公开点赞列表
+func (s *LikeService) ListLikedVideos(ctx context.Context, viewerAccountID uint, req ListLikedVideosRequest) (*ListLikedVideosResponse, error) {
+	accountID := req.AccountID
+	if accountID == 0 {
+		accountID = viewerAccountID
+	}
+	if accountID == 0 {
+		return nil, errors.New("account_id is required")
+	}
+	if accountID != viewerAccountID {
+		owner, err := s.accounts.FindByID(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if !owner.LikesPublic {
+			return nil, ErrLikesPrivate
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	var before time.Time
+	if req.BeforeTime > 0 {
+		before = time.UnixMilli(req.BeforeTime)
+	}
+	likes, err := s.repo.ListLikes(ctx, accountID, viewerAccountID, before, req.BeforeLikeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListLikedVideosResponse{Videos: []LikedVideo{}}
+	if len(likes) > limit {
+		likes = likes[:limit]
+		resp.HasMore = true
+	}
+	if len(likes) == 0 {
+		return resp, nil
+	}
+	last := likes[len(likes)-1]
+	resp.NextBeforeTime = last.CreatedAt.UnixMilli()
+	resp.NextBeforeLikeID = last.ID
+
+	ids := make([]uint, 0, len(likes))
+	for _, like := range likes {
+		ids = append(ids, like.VideoID)
+	}
+	videos, err := s.VideoRepo.ListVisibleByIDs(ctx, ids, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Video, len(videos))
+	for i := range videos {
+		byID[videos[i].ID] = &videos[i]
+	}
+	for _, like := range likes {
+		v, ok := byID[like.VideoID]
+		if !ok {
+			continue
+		}
+		resp.Videos = append(resp.Videos, LikedVideo{LikeID: like.ID, LikedAt: like.CreatedAt.UnixMilli(), Video: v})
+	}
+	return resp, nil
 }
@@ -0,0 +1,58 @@
+package video
+
+import "time"
+
+const (
+	MediaKindVideo = "video"
+	MediaKindCover = "cover"
+)
+
+// 视频文件的 HLS 打包状态，空字符串表示尚未打包
+const (
+	HLSStatusPending    = "pending"
+	HLSStatusProcessing = "processing"
+	HLSStatusReady      = "ready"
+	HLSStatusFailed     = "failed"
+)
+
+// 上传后未被任何视频引用的对象为 pending，被视频引用后为 linked；引用全部释放后回到 pending
+const (
+	MediaStatusPending = "pending"
+	MediaStatusLinked  = "linked"
+)
+
+// MediaObject 通过内容校验的上传文件及其元数据，发布时据此确认 play_url/cover_url 合法。
+// 相同内容（SHA256）的上传复用同一对象，RefCount 为引用该对象的视频数，归零时回收文件
+type MediaObject struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Key         string `gorm:"column:object_key;type:varchar(255);uniqueIndex;not null" json:"key"`
+	Kind        string `gorm:"type:varchar(16);not null;index:idx_media_hash,priority:2" json:"kind"`
+	SHA256      string `gorm:"column:sha256;type:char(64);index:idx_media_hash,priority:1" json:"sha256,omitempty"`
+	RefCount    int64  `gorm:"not null;default:0" json:"ref_count"`
+	Status      string `gorm:"type:varchar(16);not null;default:pending;index" json:"status"`
+	OwnerID     uint   `gorm:"index;not null" json:"owner_id"`
+	Size        int64  `gorm:"not null" json:"size"`
+	ContentType string `gorm:"type:varchar(64);not null" json:"content_type"`
+	DurationMs  int64  `gorm:"not null;default:0" json:"duration_ms,omitempty"`
+	Width       int    `gorm:"not null;default:0" json:"width"`
+	Height      int    `gorm:"not null;default:0" json:"height"`
+	VideoCodec  string `gorm:"type:varchar(16)" json:"video_codec,omitempty"`
+	AudioCodec  string `gorm:"type:varchar(16)" json:"audio_codec,omitempty"`
+	// 视频的 HLS 打包状态和播放列表 key
+	HLSStatus string `gorm:"column:hls_status;type:varchar(16);not null;default:''" json:"hls_status,omitempty"`
+	HLSKey    string `gorm:"column:hls_key;type:varchar(255)" json:"hls_key,omitempty"`
+	// 封面缩略图 key，键为宽度标签（720w/360w/120w），原图不够宽的档位不生成
+	Variants  map[string]string `gorm:"serializer:json;type:text" json:"variants,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// 最近一次被视频或头像引用的时间
+	LinkedAt *time.Time `json:"linked_at,omitempty"`
+	// 最近一次被重复上传复用的时间，刚被复用的对象即使引用归零也暂不回收
+	ReusedAt *time.Time `json:"-"`
+}
+
+// MediaGrant 上传过该对象的账号，复用对象后其他上传者也可以在发布时引用
+type MediaGrant struct {
+	ObjectID  uint `gorm:"primaryKey"`
+	AccountID uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
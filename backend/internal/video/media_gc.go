@@ -0,0 +1,162 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"feedsystem_video_go/internal/media"
+	"feedsystem_video_go/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// 上传目录前缀，与 Upload/uploadKey 生成的 key 一致
+var orphanScanPrefixes = []string{MediaKindVideo + "s/", MediaKindCover + "s/"}
+
+type OrphanObject struct {
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"`
+	Registered bool      `json:"registered"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+type SweepReport struct {
+	DryRun     bool           `json:"dry_run"`
+	Before     time.Time      `json:"before"`
+	Orphans    []OrphanObject `json:"orphans"`
+	Deleted    int            `json:"deleted"`
+	FreedBytes int64          `json:"freed_bytes"`
+	Failed     int            `json:"failed"`
+}
+
+// MediaSweeper 回收上传后一直未被视频引用的文件：
+// 登记过的 pending 对象按 MediaObject 清理；没有登记的文件（预签名直传后未发布、旧数据）按存储目录扫描，
+// 被视频或账号头像引用的 key 一律保留
+type MediaSweeper struct {
+	media  *MediaService
+	videos *VideoRepository
+	grace  time.Duration
+}
+
+func NewMediaSweeper(media *MediaService, videos *VideoRepository, grace time.Duration) *MediaSweeper {
+	if grace <= 0 {
+		grace = 24 * time.Hour
+	}
+	return &MediaSweeper{media: media, videos: videos, grace: grace}
+}
+
+// Sweep 清理早于宽限期的孤儿文件，dryRun 时只生成报告不删除
+func (s *MediaSweeper) Sweep(ctx context.Context, dryRun bool) (*SweepReport, error) {
+	report := &SweepReport{DryRun: dryRun, Before: time.Now().Add(-s.grace)}
+	if err := s.sweepRegistered(ctx, report); err != nil {
+		return report, err
+	}
+	for _, prefix := range orphanScanPrefixes {
+		if err := s.sweepUnregistered(ctx, prefix, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (s *MediaSweeper) sweepRegistered(ctx context.Context, report *SweepReport) error {
+	var afterID uint
+	for {
+		objs, err := s.media.repo.ListOrphans(ctx, report.Before, afterID, 200)
+		if err != nil {
+			return err
+		}
+		if len(objs) == 0 {
+			return nil
+		}
+		for i := range objs {
+			obj := &objs[i]
+			afterID = obj.ID
+			referenced, err := s.referenced(ctx, obj.Key)
+			if err != nil {
+				return err
+			}
+			if referenced {
+				continue
+			}
+			report.Orphans = append(report.Orphans, OrphanObject{Key: obj.Key, Size: obj.Size, Registered: true, UploadedAt: obj.CreatedAt})
+			if report.DryRun {
+				continue
+			}
+			deleted, err := s.media.repo.DeleteIfUnreferenced(ctx, obj.ID, report.Before)
+			if err != nil {
+				log.Printf("media sweeper: failed to delete %s: %v", obj.Key, err)
+				report.Failed++
+				continue
+			}
+			if !deleted {
+				continue
+			}
+			if err := s.media.deleteObjectFiles(ctx, obj); err != nil {
+				log.Printf("media sweeper: failed to remove file %s: %v", obj.Key, err)
+				report.Failed++
+				continue
+			}
+			report.Deleted++
+			report.FreedBytes += obj.Size
+		}
+	}
+}
+
+func (s *MediaSweeper) sweepUnregistered(ctx context.Context, prefix string, report *SweepReport) error {
+	var orphans []OrphanObject
+	err := s.media.store.List(ctx, prefix, func(info storage.ObjectInfo) error {
+		if !info.LastModified.Before(report.Before) {
+			return nil
+		}
+		// 缩略图跟随原图，原图仍登记或被引用时保留
+		key := info.Key
+		if src, ok := media.VariantSource(key); ok {
+			key = src
+		}
+		_, err := s.media.repo.GetByKey(ctx, key)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		referenced, err := s.referenced(ctx, key)
+		if err != nil || referenced {
+			return err
+		}
+		orphans = append(orphans, OrphanObject{Key: info.Key, Size: info.Size, UploadedAt: info.LastModified})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.Orphans = append(report.Orphans, orphans...)
+	if report.DryRun {
+		return nil
+	}
+	// 遍历结束后再删除，避免边遍历边修改目录
+	for _, o := range orphans {
+		if err := s.media.deleteFile(ctx, o.Key); err != nil {
+			log.Printf("media sweeper: failed to remove file %s: %v", o.Key, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+		report.FreedBytes += o.Size
+	}
+	return nil
+}
+
+func (s *MediaSweeper) referenced(ctx context.Context, key string) (bool, error) {
+	videos, err := s.videos.ListByMediaKey(ctx, key, 1)
+	if err != nil {
+		return false, err
+	}
+	if len(videos) > 0 {
+		return true, nil
+	}
+	return s.media.repo.IsAvatar(ctx, key)
+}
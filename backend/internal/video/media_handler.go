@@ -0,0 +1,133 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/media"
+	"feedsystem_video_go/internal/middleware/jwt"
+	"feedsystem_video_go/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	privateMediaCacheControl = "private, max-age=300"
+	maxPlaylistSize          = 1 << 20
+)
+
+// MediaHandler 代替静态文件服务读取上传文件：支持 Range/条件请求。
+// 带有效签名的请求直接放行（签名只发给有权观看的用户）；无签名时仅允许已登录且有权访问的用户，防止匿名盗链
+type MediaHandler struct {
+	service *VideoService
+	media   *MediaService
+}
+
+func NewMediaHandler(service *VideoService, media *MediaService) *MediaHandler {
+	return &MediaHandler{service: service, media: media}
+}
+
+func (h *MediaHandler) Serve(c *gin.Context) {
+	key, err := storage.CleanKey(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	var cacheControl string
+	if expiresAt, ok := h.media.VerifyURL(key, c.Query("exp"), c.Query("sig")); ok {
+		// 签名地址在有效期内不变，可被 CDN 和浏览器缓存到过期为止
+		cacheControl = fmt.Sprintf("public, max-age=%d", int64(time.Until(expiresAt)/time.Second))
+	} else {
+		viewerAccountID, err := jwt.GetAccountID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "invalid or expired signature"})
+			return
+		}
+		allowed, err := h.service.MediaAccess(c.Request.Context(), key, viewerAccountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !allowed {
+			// 不暴露私密文件是否存在
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		cacheControl = privateMediaCacheControl
+	}
+
+	f, obj, err := h.media.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	info := f.Info()
+
+	if media.IsPlaylist(key) {
+		h.servePlaylist(c, key, f, cacheControl)
+		return
+	}
+
+	contentType := info.ContentType
+	etag := info.ETag
+	if obj != nil {
+		contentType = obj.ContentType
+		if obj.SHA256 != "" {
+			etag = `"` + obj.SHA256 + `"`
+		}
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("X-Content-Type-Options", "nosniff")
+	if etag != "" {
+		header.Set("ETag", etag)
+	}
+	header.Set("Cache-Control", cacheControl)
+	if cacheControl == privateMediaCacheControl {
+		header.Set("Vary", "Authorization")
+	}
+	// ServeContent 处理 Range、If-Range、If-None-Match、If-Modified-Since 和 HEAD
+	http.ServeContent(c.Writer, c.Request, "", info.LastModified, f)
+}
+
+// 播放列表中的分片地址是相对路径，输出前逐个替换为签名地址，播放器无需携带登录态即可拉取分片
+func (h *MediaHandler) servePlaylist(c *gin.Context, key string, f io.Reader, cacheControl string) {
+	data, err := io.ReadAll(io.LimitReader(f, maxPlaylistSize))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	dir := path.Dir(key)
+	data = media.RewritePlaylist(data, func(uri string) string {
+		if media.IsExternal(uri) || strings.HasPrefix(uri, "/") {
+			return uri
+		}
+		return h.media.SignURL(path.Join(dir, uri))
+	})
+	header := c.Writer.Header()
+	header.Set("Cache-Control", cacheControl)
+	if cacheControl == privateMediaCacheControl {
+		header.Set("Vary", "Authorization")
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", data)
+}
@@ -0,0 +1,34 @@
+package video
+
+import "context"
+
+// MigrateMediaKeys 把旧数据中的完整地址（/static/...、对象存储地址）转换为存储 key，外部地址保持不变。
+// 可重复执行，返回更新的视频数
+func MigrateMediaKeys(ctx context.Context, repo *VideoRepository, media *MediaService) (int, error) {
+	updated := 0
+	var afterID uint
+	for {
+		videos, err := repo.ListWithURLKeys(ctx, afterID, 200)
+		if err != nil {
+			return updated, err
+		}
+		if len(videos) == 0 {
+			return updated, nil
+		}
+		for _, v := range videos {
+			afterID = v.ID
+			playKey := media.NormalizeKey(v.PlayKey)
+			coverKey := media.NormalizeKey(v.CoverKey)
+			if playKey == v.PlayKey && coverKey == v.CoverKey {
+				continue
+			}
+			if err := repo.UpdateVideo(ctx, v.ID, map[string]interface{}{
+				"play_key":  playKey,
+				"cover_key": coverKey,
+			}); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package video
+
+import (
+	"context"
+	"feedsystem_video_go/internal/account"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+func (r *MediaRepository) Create(ctx context.Context, obj *MediaObject) error {
+	return r.db.WithContext(ctx).Create(obj).Error
+}
+
+func (r *MediaRepository) GetByKey(ctx context.Context, key string) (*MediaObject, error) {
+	var obj MediaObject
+	if err := r.db.WithContext(ctx).Where("object_key = ?", key).First(&obj).Error; err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+// IsAvatar key 是否被用作账号头像
+func (r *MediaRepository) IsAvatar(ctx context.Context, key string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&account.Account{}).Where("avatar_key = ?", key).Count(&count).Error
+	return count > 0, err
+}
+
+// IsReferenced key 是否仍被未删除的视频（播放文件或封面）或账号头像引用
+func (r *MediaRepository) IsReferenced(ctx context.Context, key string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Video{}).
+		Where("play_key = ? OR cover_key = ?", key, key).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	return r.IsAvatar(ctx, key)
+}
+
+func (r *MediaRepository) GetBySHA256(ctx context.Context, sha256 string, kind string) (*MediaObject, error) {
+	var obj MediaObject
+	if err := r.db.WithContext(ctx).
+		Where("sha256 = ? AND kind = ?", sha256, kind).
+		Order("id ASC").
+		First(&obj).Error; err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (r *MediaRepository) MarkReused(ctx context.Context, id uint, t time.Time) error {
+	return r.db.WithContext(ctx).Model(&MediaObject{}).Where("id = ?", id).Update("reused_at", t).Error
+}
+
+func (r *MediaRepository) Grant(ctx context.Context, objectID, accountID uint) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&MediaGrant{ObjectID: objectID, AccountID: accountID}).Error
+}
+
+func (r *MediaRepository) HasGrant(ctx context.Context, objectID, accountID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&MediaGrant{}).
+		Where("object_id = ? AND account_id = ?", objectID, accountID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IncrRef 增加引用并标记为 linked
+func (r *MediaRepository) IncrRef(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&MediaObject{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"ref_count": gorm.Expr("ref_count + 1"),
+			"status":    MediaStatusLinked,
+			"linked_at": time.Now(),
+		}).Error
+}
+
+// DecrRef 减少引用，归零时回到 pending（MySQL 按书写顺序赋值，status 判断的是减后的 ref_count）
+func (r *MediaRepository) DecrRef(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Exec(
+		"UPDATE media_objects SET ref_count = ref_count - 1, status = IF(ref_count = 0, ?, status) WHERE id = ? AND ref_count > 0",
+		MediaStatusPending, id).Error
+}
+
+// MarkHLSPending 尚未打包的对象标记为待打包，返回是否由本次调用标记
+func (r *MediaRepository) MarkHLSPending(ctx context.Context, id uint) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&MediaObject{}).
+		Where("id = ? AND hls_status = ?", id, "").
+		Update("hls_status", HLSStatusPending)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *MediaRepository) SetHLS(ctx context.Context, id uint, status, hlsKey string) error {
+	return r.db.WithContext(ctx).Model(&MediaObject{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"hls_status": status, "hls_key": hlsKey}).Error
+}
+
+// ListOrphans 未被引用、早于 before 上传且近期未被复用的对象，按 id 游标分批
+func (r *MediaRepository) ListOrphans(ctx context.Context, before time.Time, afterID uint, limit int) ([]MediaObject, error) {
+	var objs []MediaObject
+	if err := r.db.WithContext(ctx).
+		Where("id > ? AND status = ? AND ref_count = 0 AND created_at < ?", afterID, MediaStatusPending, before).
+		Where("reused_at IS NULL OR reused_at < ?", before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&objs).Error; err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// DeleteIfUnreferenced 仅在引用为零且近期未被复用时删除，返回是否删除
+func (r *MediaRepository) DeleteIfUnreferenced(ctx context.Context, id uint, reusedBefore time.Time) (bool, error) {
+	deleted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND ref_count = 0 AND (reused_at IS NULL OR reused_at < ?)", id, reusedBefore).
+			Delete(&MediaObject{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		return tx.Where("object_id = ?", id).Delete(&MediaGrant{}).Error
+	})
+	return deleted, err
+}
+
+// SumSizeByOwner 账号名下对象占用的存储空间
+func (r *MediaRepository) SumSizeByOwner(ctx context.Context, ownerID uint) (int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&MediaObject{}).
+		Where("owner_id = ?", ownerID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *MediaRepository) CountByOwnerSince(ctx context.Context, ownerID uint, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&MediaObject{}).
+		Where("owner_id = ? AND created_at >= ?", ownerID, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,403 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"feedsystem_video_go/internal/media"
+	"feedsystem_video_go/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxCoverDimension = 4096
+	// 被重复上传复用后的保护期，期间引用归零也不回收，留给复用者发布
+	mediaReuseGrace = 24 * time.Hour
+)
+
+var ErrMediaNotFound = errors.New("media not found, upload it first")
+
+// MediaService 上传内容校验：视频解析 MP4 box，封面识别图片头，通过后写入存储并登记 MediaObject
+type MediaService struct {
+	repo   *MediaRepository
+	store  storage.Backend
+	signer *media.URLSigner
+}
+
+func NewMediaService(repo *MediaRepository, store storage.Backend, signer *media.URLSigner) *MediaService {
+	return &MediaService{repo: repo, store: store, signer: signer}
+}
+
+// SignURL 根据存储 key 生成带签名的访问地址，外部地址原样返回
+func (s *MediaService) SignURL(key string) string {
+	return s.signer.Sign(key)
+}
+
+// SignVariants 缩略图地址，标签到签名地址
+func (s *MediaService) SignVariants(keys map[string]string) map[string]string {
+	return s.signer.SignVariants(keys)
+}
+
+// VerifyURL 校验媒体地址上的签名参数，返回签名过期时间
+func (s *MediaService) VerifyURL(key, exp, sig string) (time.Time, bool) {
+	return s.signer.Verify(key, exp, sig)
+}
+
+// FillURLs 响应前为视频填充 play_url/cover_url
+func (s *MediaService) FillURLs(videos ...*Video) {
+	for _, v := range videos {
+		if v == nil {
+			continue
+		}
+		v.MP4URL = s.signer.Sign(v.PlayKey)
+		v.PlayURL = v.MP4URL
+		if v.HLSStatus == HLSStatusReady && v.HLSKey != "" {
+			v.PlayURL = s.signer.Sign(v.HLSKey)
+		}
+		v.CoverURL = s.signer.Sign(v.CoverKey)
+		v.CoverVariants = s.signer.SignVariants(v.CoverVariantKeys)
+	}
+}
+
+// NormalizeKey 把客户端传入的 key 或地址（签名地址、旧 /static 地址、对象存储地址）统一成存储 key，外部地址原样保留
+func (s *MediaService) NormalizeKey(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if key, ok := s.signer.KeyFromURL(raw); ok {
+		if key, err := storage.CleanKey(key); err == nil {
+			return key
+		}
+	}
+	if key, ok := s.store.KeyFromURL(raw); ok {
+		return key
+	}
+	if !media.IsExternal(raw) {
+		if key, err := storage.CleanKey(raw); err == nil {
+			return key
+		}
+	}
+	return raw
+}
+
+// Upload 校验内容并计算 SHA-256，相同内容复用已有对象，否则写入存储；r 需要可 Seek 以便多次读取
+func (s *MediaService) Upload(ctx context.Context, kind string, ownerID uint, filename string, r io.ReadSeeker, size int64) (*MediaObject, error) {
+	obj, err := probe(kind, filename, r, size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	obj.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	if existing, err := s.repo.GetBySHA256(ctx, obj.SHA256, kind); err == nil {
+		if err := s.repo.MarkReused(ctx, existing.ID, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := s.repo.Grant(ctx, existing.ID, ownerID); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	obj.Key = path.Join(kind+"s", fmt.Sprintf("%d", ownerID), time.Now().Format("20060102"), randHex(16)+ext)
+	obj.OwnerID = ownerID
+	if err := s.store.Put(ctx, obj.Key, r, size, obj.ContentType); err != nil {
+		return nil, err
+	}
+	if kind == MediaKindCover {
+		obj.Variants = s.generateVariants(ctx, obj.Key, r)
+	}
+	if err := s.repo.Create(ctx, obj); err != nil {
+		s.deleteObjectFiles(context.Background(), obj)
+		return nil, err
+	}
+	if err := s.repo.Grant(ctx, obj.ID, ownerID); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Lookup 发布时根据 key 查找媒体对象：外部地址返回 nil；
+// 预签名直传的对象首次引用时在此补做内容校验，不合法的文件直接删除
+func (s *MediaService) Lookup(ctx context.Context, key string, kind string, ownerID uint) (*MediaObject, error) {
+	if key == "" || media.IsExternal(key) {
+		return nil, nil
+	}
+	obj, err := s.repo.GetByKey(ctx, key)
+	if err == nil {
+		if obj.Kind != kind {
+			return nil, ErrMediaNotFound
+		}
+		if obj.OwnerID != ownerID {
+			granted, err := s.repo.HasGrant(ctx, obj.ID, ownerID)
+			if err != nil {
+				return nil, err
+			}
+			if !granted {
+				return nil, ErrMediaNotFound
+			}
+		}
+		return obj, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !ownsKey(key, kind, ownerID) {
+		return nil, ErrMediaNotFound
+	}
+
+	f, err := s.store.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+	obj, err = probe(kind, key, f, f.Info().Size)
+	if err != nil {
+		if delErr := s.store.Delete(context.Background(), key); delErr != nil {
+			log.Printf("media service: failed to remove invalid object %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+	// 直传对象不在服务端计算哈希，不参与去重
+	obj.Key = key
+	obj.OwnerID = ownerID
+	if kind == MediaKindCover {
+		obj.Variants = s.generateVariants(ctx, key, f)
+	}
+	if err := s.repo.Create(ctx, obj); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Grant(ctx, obj.ID, ownerID); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Retain 视频引用该对象，外部地址或 nil 忽略
+func (s *MediaService) Retain(ctx context.Context, obj *MediaObject) {
+	if obj == nil {
+		return
+	}
+	if err := s.repo.IncrRef(ctx, obj.ID); err != nil {
+		log.Printf("media service: failed to retain %s: %v", obj.Key, err)
+	}
+}
+
+// RetainCover 账号头像引用上传的封面图片，校验方式与发布时的封面相同
+func (s *MediaService) RetainCover(ctx context.Context, key string, ownerID uint) error {
+	obj, err := s.Lookup(ctx, key, MediaKindCover, ownerID)
+	if err != nil {
+		return err
+	}
+	s.Retain(ctx, obj)
+	return nil
+}
+
+// Release 视频不再引用该 key：引用归零时删除对象和文件；未登记的旧上传文件没有引用计数，
+// 只有不再被任何视频或头像引用时才删除
+func (s *MediaService) Release(ctx context.Context, key string) error {
+	if key == "" || media.IsExternal(key) {
+		return nil
+	}
+	obj, err := s.repo.GetByKey(ctx, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		referenced, err := s.repo.IsReferenced(ctx, key)
+		if err != nil || referenced {
+			return err
+		}
+		return s.deleteFile(ctx, key)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DecrRef(ctx, obj.ID); err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteIfUnreferenced(ctx, obj.ID, time.Now().Add(-mediaReuseGrace))
+	if err != nil || !deleted {
+		return err
+	}
+	return s.deleteObjectFiles(ctx, obj)
+}
+
+// deleteObjectFiles 删除对象文件及其缩略图、HLS 分片
+func (s *MediaService) deleteObjectFiles(ctx context.Context, obj *MediaObject) error {
+	for _, key := range obj.Variants {
+		if err := s.deleteFile(ctx, key); err != nil {
+			log.Printf("media service: failed to remove variant %s: %v", key, err)
+		}
+	}
+	if obj.Kind == MediaKindVideo {
+		if err := s.deleteHLS(ctx, obj.Key); err != nil {
+			log.Printf("media service: failed to remove hls files of %s: %v", obj.Key, err)
+		}
+	}
+	return s.deleteFile(ctx, obj.Key)
+}
+
+func (s *MediaService) deleteHLS(ctx context.Context, playKey string) error {
+	var keys []string
+	if err := s.store.List(ctx, media.HLSKey(playKey, ""), func(info storage.ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.deleteFile(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateVariants 解码封面并按 CoverVariantWidths 生成 JPEG 缩略图，失败的档位跳过，客户端回退到原图。
+// 标准库不含 WebP 解码，WebP 封面不生成缩略图
+func (s *MediaService) generateVariants(ctx context.Context, key string, r io.ReadSeeker) map[string]string {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		log.Printf("media service: skip variants for %s: %v", key, err)
+		return nil
+	}
+	src := media.Flatten(img)
+	variants := make(map[string]string)
+	for _, w := range media.CoverVariantWidths {
+		if w >= src.Bounds().Dx() {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := media.EncodeJPEG(&buf, media.Thumbnail(src, w)); err != nil {
+			log.Printf("media service: encode variant %dw for %s: %v", w, key, err)
+			continue
+		}
+		label := media.VariantLabel(w)
+		vk := media.VariantKey(key, label)
+		if err := s.store.Put(ctx, vk, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "image/jpeg"); err != nil {
+			log.Printf("media service: store variant %s: %v", vk, err)
+			continue
+		}
+		variants[label] = vk
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+	return variants
+}
+
+func (s *MediaService) deleteFile(ctx context.Context, key string) error {
+	if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// 直传对象的 key 由 PresignUpload 生成，形如 <kind>s/<ownerID>/...
+func ownsKey(key, kind string, ownerID uint) bool {
+	return strings.HasPrefix(key, fmt.Sprintf("%ss/%d/", kind, ownerID))
+}
+
+func probe(kind, filename string, r io.ReadSeeker, size int64) (*MediaObject, error) {
+	switch kind {
+	case MediaKindVideo:
+		if strings.ToLower(filepath.Ext(filename)) != ".mp4" {
+			return nil, errors.New("only .mp4 is allowed")
+		}
+		meta, err := media.ProbeMP4(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return &MediaObject{
+			Kind:        kind,
+			Size:        size,
+			ContentType: "video/mp4",
+			DurationMs:  meta.DurationMs,
+			Width:       meta.Width,
+			Height:      meta.Height,
+			VideoCodec:  meta.VideoCodec,
+			AudioCodec:  meta.AudioCodec,
+		}, nil
+	case MediaKindCover:
+		meta, err := media.ProbeImage(r)
+		if err != nil {
+			return nil, err
+		}
+		if !meta.MatchExt(filepath.Ext(filename)) {
+			return nil, errors.New("file content does not match its extension")
+		}
+		if meta.Width > maxCoverDimension || meta.Height > maxCoverDimension {
+			return nil, fmt.Errorf("cover must be at most %dx%d", maxCoverDimension, maxCoverDimension)
+		}
+		return &MediaObject{
+			Kind:        kind,
+			Size:        size,
+			ContentType: meta.ContentType(),
+			Width:       meta.Width,
+			Height:      meta.Height,
+		}, nil
+	default:
+		return nil, errors.New("unknown media kind")
+	}
+}
+
+// IsUploader 账号是否上传过该 key 对应的文件
+func (s *MediaService) IsUploader(ctx context.Context, key string, accountID uint) (bool, error) {
+	if accountID == 0 {
+		return false, nil
+	}
+	obj, err := s.repo.GetByKey(ctx, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 未登记的文件按 key 中的账号目录判断
+		return ownsKey(key, MediaKindVideo, accountID) || ownsKey(key, MediaKindCover, accountID), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if obj.OwnerID == accountID {
+		return true, nil
+	}
+	return s.repo.HasGrant(ctx, obj.ID, accountID)
+}
+
+// Open 打开存储中的文件，同时返回登记的媒体对象（可能为 nil）
+func (s *MediaService) Open(ctx context.Context, key string) (storage.Object, *MediaObject, error) {
+	f, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := s.repo.GetByKey(ctx, key)
+	if err != nil {
+		obj = nil
+	}
+	return f, obj, nil
+}
@@ -0,0 +1,26 @@
+package video
+
+type ReportPlayRequest struct {
+	VideoID uint `json:"video_id"`
+	// 本次观看时长(毫秒)
+	WatchDurationMs int64 `json:"watch_duration_ms"`
+	// 观看进度 0-100，为空时按时长和视频总时长估算
+	CompletionPercent int `json:"completion_percent"`
+	// 当前播放位置(毫秒)，登录用户用于续播
+	PositionMs int64 `json:"position_ms"`
+}
+
+type ReportPlayResponse struct {
+	// 本次上报是否计入播放量（同一观众在去重窗口内只计一次）
+	Counted bool `json:"counted"`
+	// 本次上报是否计入完播
+	Completed bool `json:"completed"`
+}
+
+// PlayStats 待写回 MySQL 的播放统计增量
+type PlayStats struct {
+	Views       int64
+	Completions int64
+	WatchMs     int64
+	Popularity  int64
+}
@@ -0,0 +1,54 @@
+package video
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"feedsystem_video_go/internal/middleware/jwt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PlayHandler struct {
+	service *PlayService
+}
+
+func NewPlayHandler(service *PlayService) *PlayHandler {
+	return &PlayHandler{service: service}
+}
+
+func (ph *PlayHandler) ReportPlay(c *gin.Context) {
+	var req ReportPlayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "video_id is required"})
+		return
+	}
+	if req.WatchDurationMs < 0 || req.CompletionPercent < 0 || req.CompletionPercent > 100 {
+		c.JSON(400, gin.H{"error": "invalid watch_duration_ms or completion_percent"})
+		return
+	}
+
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	resp, err := ph.service.ReportPlay(c.Request.Context(), req, viewerAccountID, viewerKey(c, viewerAccountID))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
+
+// 登录用户按账号去重，未登录用户按 IP + User-Agent 去重
+func viewerKey(c *gin.Context, accountID uint) string {
+	if accountID != 0 {
+		return "a:" + strconv.FormatUint(uint64(accountID), 10)
+	}
+	sum := sha1.Sum([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return "c:" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,224 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	playStatsPendingKey = "play:stats:pending"
+	// 一次播放和一次完播分别带来的热度
+	playViewPopularity       = 1
+	playCompletionPopularity = 1
+	// 视频时长未知时单次上报的观看时长上限
+	maxWatchDurationMs = int64(6 * time.Hour / time.Millisecond)
+)
+
+type PlayService struct {
+	videos            *VideoService
+	repo              *VideoRepository
+	cache             *rediscache.Client
+	popularityMQ      *rabbitmq.PopularityMQ
+	history           *HistoryService
+	window            time.Duration
+	completionPercent int
+}
+
+func NewPlayService(videos *VideoService, repo *VideoRepository, cache *rediscache.Client, popularityMQ *rabbitmq.PopularityMQ, history *HistoryService, window time.Duration, completionPercent int) *PlayService {
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	if completionPercent <= 0 || completionPercent > 100 {
+		completionPercent = 90
+	}
+	return &PlayService{videos: videos, repo: repo, cache: cache, popularityMQ: popularityMQ, history: history, window: window, completionPercent: completionPercent}
+}
+
+// ReportPlay 记录一次播放，viewerKey 标识观众（登录用户为账号，未登录为客户端指纹）
+func (s *PlayService) ReportPlay(ctx context.Context, req ReportPlayRequest, viewerAccountID uint, viewerKey string) (*ReportPlayResponse, error) {
+	if req.VideoID == 0 {
+		return nil, errors.New("video_id is required")
+	}
+	if viewerKey == "" {
+		return nil, errors.New("viewer is required")
+	}
+	video, err := s.videos.GetDetail(ctx, req.VideoID, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	// 作者预览草稿不计入统计
+	if video.Status != StatusPublished && video.Status != "" {
+		return &ReportPlayResponse{}, nil
+	}
+
+	watchMs := req.WatchDurationMs
+	limit := maxWatchDurationMs
+	if video.DurationMs > 0 {
+		limit = video.DurationMs
+	}
+	if watchMs < 0 {
+		watchMs = 0
+	}
+	if watchMs > limit {
+		watchMs = limit
+	}
+	completion := req.CompletionPercent
+	if completion <= 0 && video.DurationMs > 0 {
+		completion = int(watchMs * 100 / video.DurationMs)
+	}
+	if completion > 100 {
+		completion = 100
+	}
+
+	resp := &ReportPlayResponse{}
+	if s.cache == nil {
+		// 没有 Redis 时无法去重，每次上报都计数
+		resp.Counted = true
+		resp.Completed = completion >= s.completionPercent
+	} else {
+		bucket := time.Now().Unix() / int64(s.window/time.Second)
+		resp.Counted, err = s.cache.PFAdd(ctx, fmt.Sprintf("play:views:%d:%d", video.ID, bucket), viewerKey, s.window)
+		if err != nil {
+			return nil, err
+		}
+		if completion >= s.completionPercent {
+			resp.Completed, err = s.cache.PFAdd(ctx, fmt.Sprintf("play:completes:%d:%d", video.ID, bucket), viewerKey, s.window)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	stats := PlayStats{WatchMs: watchMs}
+	if resp.Counted {
+		stats.Views = 1
+		stats.Popularity += playViewPopularity
+	}
+	if resp.Completed {
+		stats.Completions = 1
+		stats.Popularity += playCompletionPopularity
+	}
+	if err := s.recordStats(ctx, video.ID, stats); err != nil {
+		return nil, err
+	}
+
+	if viewerAccountID != 0 && s.history != nil {
+		// 看完的视频下次从头播放
+		position := req.PositionMs
+		if position < 0 || completion >= s.completionPercent {
+			position = 0
+		}
+		if video.DurationMs > 0 && position > video.DurationMs {
+			position = video.DurationMs
+		}
+		_ = s.history.Record(ctx, viewerAccountID, video.ID, position)
+	}
+
+	if stats.Popularity > 0 {
+		enqueued := false
+		if s.popularityMQ != nil {
+			if err := s.popularityMQ.Update(ctx, video.ID, stats.Popularity); err == nil {
+				enqueued = true
+			}
+		}
+		if !enqueued {
+			UpdatePopularityCache(ctx, s.cache, video.ID, stats.Popularity)
+		}
+	}
+	return resp, nil
+}
+
+// 播放统计先累加在 Redis，由 worker 定期批量写回 MySQL
+func (s *PlayService) recordStats(ctx context.Context, id uint, stats PlayStats) error {
+	if stats == (PlayStats{}) {
+		return nil
+	}
+	if s.cache != nil {
+		if err := pushPlayStats(ctx, s.cache, id, stats); err == nil {
+			return nil
+		}
+	}
+	return s.repo.AddPlayStats(ctx, id, stats)
+}
+
+// pushPlayStats 在一个事务中累加各字段，失败时整体未写入，调用方可以安全地改为直接写库
+func pushPlayStats(ctx context.Context, cache *rediscache.Client, id uint, stats PlayStats) error {
+	return cache.HIncrByFields(ctx, playStatsPendingKey, map[string]int64{
+		fmt.Sprintf("%d:v", id): stats.Views,
+		fmt.Sprintf("%d:c", id): stats.Completions,
+		fmt.Sprintf("%d:w", id): stats.WatchMs,
+		fmt.Sprintf("%d:p", id): stats.Popularity,
+	})
+}
+
+// RecoverPlayStats 把上次进程在 HDrain 途中退出留下的临时 hash 合并回待写入的统计
+func RecoverPlayStats(ctx context.Context, cache *rediscache.Client) (int, error) {
+	if cache == nil {
+		return 0, nil
+	}
+	// 正常的 drain 在毫秒级完成，留出足够余量避免抢走其他实例正在处理的临时 hash
+	return cache.HRecoverDrains(ctx, playStatsPendingKey, time.Minute)
+}
+
+// FlushPlayStats 把 Redis 中累加的播放统计写回 MySQL，返回更新的视频数；写失败的增量放回 Redis 等待下次重试
+func FlushPlayStats(ctx context.Context, cache *rediscache.Client, repo *VideoRepository) (int, error) {
+	if cache == nil || repo == nil {
+		return 0, nil
+	}
+	values, err := cache.HDrain(ctx, playStatsPendingKey)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := make(map[uint]*PlayStats)
+	for field, raw := range values {
+		idPart, suffix, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idPart, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		stats := pending[uint(id)]
+		if stats == nil {
+			stats = &PlayStats{}
+			pending[uint(id)] = stats
+		}
+		switch suffix {
+		case "v":
+			stats.Views += n
+		case "c":
+			stats.Completions += n
+		case "w":
+			stats.WatchMs += n
+		case "p":
+			stats.Popularity += n
+		}
+	}
+
+	flushed := 0
+	var firstErr error
+	for id, stats := range pending {
+		if err := repo.AddPlayStats(ctx, id, *stats); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			_ = pushPlayStats(context.Background(), cache, id, *stats)
+			continue
+		}
+		flushed++
+		_ = cache.Del(ctx, fmt.Sprintf("video:detail:id=%d", id))
+	}
+	return flushed, firstErr
+}
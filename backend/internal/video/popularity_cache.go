@@ -27,3 +27,28 @@ func UpdatePopularityCache(ctx context.Context, cache *rediscache.Client, id uin
 	_ = cache.ZincrBy(opCtx, windowKey, member, float64(change))
 	_ = cache.Expire(opCtx, windowKey, 2*time.Hour)
 }
+
+// 从热榜时间窗和合并快照中移除视频
+func RemoveFromPopularityCache(ctx context.Context, cache *rediscache.Client, id uint) {
+	if cache == nil || id == 0 {
+		return
+	}
+
+	_ = cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	member := strconv.FormatUint(uint64(id), 10)
+
+	opCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	const win = 60
+	for i := 0; i < win; i++ {
+		minute := now.Add(-time.Duration(i) * time.Minute).Format("200601021504")
+		_ = cache.ZRem(opCtx, "hot:video:1m:"+minute, member)
+		// 快照只保留 2 分钟
+		if i < 3 {
+			_ = cache.ZRem(opCtx, "hot:video:merge:1m:"+minute, member)
+		}
+	}
+}
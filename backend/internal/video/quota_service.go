@@ -0,0 +1,152 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+)
+
+var (
+	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	ErrDailyUploadLimit     = errors.New("daily upload limit reached")
+	ErrDailyPublishLimit    = errors.New("daily publish limit reached")
+)
+
+// QuotaLimits 每个账号的配额，<=0 表示不限制
+type QuotaLimits struct {
+	StorageBytes   int64
+	DailyUploads   int64
+	DailyPublishes int64
+}
+
+// QuotaService 存储空间按 MySQL 中账号名下的 MediaObject 统计；每日次数用 Redis 计数，
+// Redis 不可用时退化为按当天创建的记录计数（只校验，不预占）
+type QuotaService struct {
+	limits QuotaLimits
+	media  *MediaRepository
+	videos *VideoRepository
+	cache  *rediscache.Client
+}
+
+func NewQuotaService(limits QuotaLimits, media *MediaRepository, videos *VideoRepository, cache *rediscache.Client) *QuotaService {
+	return &QuotaService{limits: limits, media: media, videos: videos, cache: cache}
+}
+
+// ReserveUpload 上传前检查剩余空间并占用一次当天上传次数；上传失败时调用返回的 release 归还次数
+func (q *QuotaService) ReserveUpload(ctx context.Context, accountID uint, size int64) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	if q.limits.StorageBytes > 0 {
+		used, err := q.media.SumSizeByOwner(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if used+size > q.limits.StorageBytes {
+			return nil, ErrStorageQuotaExceeded
+		}
+	}
+	return q.reserve(ctx, "uploads", accountID, q.limits.DailyUploads, ErrDailyUploadLimit, q.countUploads)
+}
+
+// ReservePublish 发布前占用一次当天发布次数
+func (q *QuotaService) ReservePublish(ctx context.Context, accountID uint) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	return q.reserve(ctx, "publishes", accountID, q.limits.DailyPublishes, ErrDailyPublishLimit, q.countPublishes)
+}
+
+func (q *QuotaService) reserve(ctx context.Context, name string, accountID uint, limit int64, limitErr error,
+	fallback func(context.Context, uint, time.Time) (int64, error)) (func(), error) {
+	noop := func() {}
+	if limit <= 0 {
+		return noop, nil
+	}
+	day := startOfDay(time.Now())
+	if q.cache != nil {
+		key := dailyQuotaKey(name, accountID, day)
+		n, err := q.cache.IncrWithTTL(ctx, key, 48*time.Hour)
+		if err == nil {
+			release := func() { _ = q.cache.Decr(context.Background(), key) }
+			if n > limit {
+				release()
+				return nil, limitErr
+			}
+			return release, nil
+		}
+	}
+	n, err := fallback(ctx, accountID, day)
+	if err != nil {
+		return nil, err
+	}
+	if n >= limit {
+		return nil, limitErr
+	}
+	return noop, nil
+}
+
+func (q *QuotaService) countUploads(ctx context.Context, accountID uint, since time.Time) (int64, error) {
+	return q.media.CountByOwnerSince(ctx, accountID, since)
+}
+
+func (q *QuotaService) countPublishes(ctx context.Context, accountID uint, since time.Time) (int64, error) {
+	return q.videos.CountPublishedSince(ctx, accountID, since)
+}
+
+func (q *QuotaService) daily(ctx context.Context, name string, accountID uint, day time.Time,
+	fallback func(context.Context, uint, time.Time) (int64, error)) (int64, error) {
+	if q.cache != nil {
+		if n, err := q.cache.GetInt(ctx, dailyQuotaKey(name, accountID, day)); err == nil {
+			return n, nil
+		}
+	}
+	return fallback(ctx, accountID, day)
+}
+
+// Usage 当前用量与剩余额度，不限制的项剩余为 -1
+func (q *QuotaService) Usage(ctx context.Context, accountID uint) (*QuotaResponse, error) {
+	now := time.Now()
+	day := startOfDay(now)
+	resp := &QuotaResponse{
+		StorageLimit:       q.limits.StorageBytes,
+		DailyUploadLimit:   q.limits.DailyUploads,
+		DailyPublishLimit:  q.limits.DailyPublishes,
+		ResetAt:            day.AddDate(0, 0, 1).Unix(),
+		StorageRemaining:   -1,
+		UploadsRemaining:   -1,
+		PublishesRemaining: -1,
+	}
+	var err error
+	if resp.StorageUsed, err = q.media.SumSizeByOwner(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if resp.UploadsToday, err = q.daily(ctx, "uploads", accountID, day, q.countUploads); err != nil {
+		return nil, err
+	}
+	if resp.PublishesToday, err = q.daily(ctx, "publishes", accountID, day, q.countPublishes); err != nil {
+		return nil, err
+	}
+	if q.limits.StorageBytes > 0 {
+		resp.StorageRemaining = max(q.limits.StorageBytes-resp.StorageUsed, 0)
+	}
+	if q.limits.DailyUploads > 0 {
+		resp.UploadsRemaining = max(q.limits.DailyUploads-resp.UploadsToday, 0)
+	}
+	if q.limits.DailyPublishes > 0 {
+		resp.PublishesRemaining = max(q.limits.DailyPublishes-resp.PublishesToday, 0)
+	}
+	return resp, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func dailyQuotaKey(name string, accountID uint, day time.Time) string {
+	return fmt.Sprintf("quota:%s:%d:%s", name, accountID, day.Format("20060102"))
+}
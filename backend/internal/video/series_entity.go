@@ -0,0 +1,85 @@
+package video
+
+import "time"
+
+// Series 作者的合集，剧集按 position 从 1 开始排列
+type Series struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	AuthorID      uint      `gorm:"index;not null" json:"author_id"`
+	Title         string    `gorm:"type:varchar(128);not null" json:"title"`
+	Description   string    `gorm:"type:varchar(255)" json:"description,omitempty"`
+	EpisodesCount int64     `gorm:"not null;default:0" json:"episodes_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SeriesEpisode 一个视频最多属于一个合集
+type SeriesEpisode struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SeriesID  uint      `gorm:"index:idx_series_position,priority:1;not null" json:"series_id"`
+	VideoID   uint      `gorm:"uniqueIndex;not null" json:"video_id"`
+	Position  int       `gorm:"index:idx_series_position,priority:2;not null" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeriesInfo 视频详情中附带的合集信息
+type SeriesInfo struct {
+	ID            uint            `json:"id"`
+	Title         string          `json:"title"`
+	Episode       int             `json:"episode"`
+	EpisodesCount int             `json:"episodes_count"`
+	Prev          *SeriesNeighbor `json:"prev,omitempty"`
+	Next          *SeriesNeighbor `json:"next,omitempty"`
+}
+
+type SeriesNeighbor struct {
+	VideoID uint   `json:"video_id"`
+	Title   string `json:"title"`
+	Episode int    `json:"episode"`
+}
+
+type CreateSeriesRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type UpdateSeriesRequest struct {
+	ID          uint    `json:"id"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type DeleteSeriesRequest struct {
+	ID uint `json:"id"`
+}
+
+type SeriesVideoRequest struct {
+	SeriesID uint `json:"series_id"`
+	VideoID  uint `json:"video_id"`
+	// 插入位置（从 1 开始），为空时追加到末尾
+	Position int `json:"position,omitempty"`
+}
+
+type ReorderSeriesRequest struct {
+	SeriesID uint `json:"series_id"`
+	// 合集内全部视频的新顺序
+	VideoIDs []uint `json:"video_ids"`
+}
+
+type GetSeriesRequest struct {
+	ID    uint `json:"id"`
+	Limit int  `json:"limit"`
+	// 游标：上一页返回的 next_after_position，首页不传
+	AfterPosition int `json:"after_position"`
+}
+
+type ListSeriesByAuthorRequest struct {
+	AuthorID uint `json:"author_id"`
+}
+
+type SeriesDetailResponse struct {
+	Series            *Series `json:"series"`
+	Videos            []Video `json:"videos"`
+	NextAfterPosition int     `json:"next_after_position"`
+	HasMore           bool    `json:"has_more"`
+}
@@ -0,0 +1,185 @@
+package video
+
+import (
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeriesHandler struct {
+	service *SeriesService
+}
+
+func NewSeriesHandler(service *SeriesService) *SeriesHandler {
+	return &SeriesHandler{service: service}
+}
+
+func (h *SeriesHandler) Create(c *gin.Context) {
+	var req CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	series, err := h.service.Create(c.Request.Context(), authorID, req.Title, req.Description)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, series)
+}
+
+func (h *SeriesHandler) Update(c *gin.Context) {
+	var req UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	series, err := h.service.Update(c.Request.Context(), req.ID, authorID, req.Title, req.Description)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, series)
+}
+
+func (h *SeriesHandler) Delete(c *gin.Context) {
+	var req DeleteSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), req.ID, authorID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "series deleted"})
+}
+
+func (h *SeriesHandler) AddVideo(c *gin.Context) {
+	var req SeriesVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SeriesID == 0 || req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "series_id and video_id are required"})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.AddVideo(c.Request.Context(), authorID, req.SeriesID, req.VideoID, req.Position); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "video added"})
+}
+
+func (h *SeriesHandler) RemoveVideo(c *gin.Context) {
+	var req SeriesVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SeriesID == 0 || req.VideoID == 0 {
+		c.JSON(400, gin.H{"error": "series_id and video_id are required"})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.RemoveVideo(c.Request.Context(), authorID, req.SeriesID, req.VideoID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "video removed"})
+}
+
+func (h *SeriesHandler) Reorder(c *gin.Context) {
+	var req ReorderSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SeriesID == 0 {
+		c.JSON(400, gin.H{"error": "series_id is required"})
+		return
+	}
+	authorID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Reorder(c.Request.Context(), authorID, req.SeriesID, req.VideoIDs); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "series reordered"})
+}
+
+func (h *SeriesHandler) Get(c *gin.Context) {
+	var req GetSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	resp, err := h.service.Get(c.Request.Context(), req.ID, viewerAccountID, req.AfterPosition, req.Limit)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, resp)
+}
+
+func (h *SeriesHandler) ListByAuthor(c *gin.Context) {
+	var req ListSeriesByAuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AuthorID == 0 {
+		c.JSON(400, gin.H{"error": "author_id is required"})
+		return
+	}
+	list, err := h.service.ListByAuthor(c.Request.Context(), req.AuthorID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, list)
+}
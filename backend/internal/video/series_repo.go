@@ -0,0 +1,153 @@
+package video
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+func (r *SeriesRepository) Create(ctx context.Context, s *Series) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+func (r *SeriesRepository) GetByID(ctx context.Context, id uint) (*Series, error) {
+	var s Series
+	if err := r.db.WithContext(ctx).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SeriesRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Series{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *SeriesRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", id).Delete(&SeriesEpisode{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Series{}, id).Error
+	})
+}
+
+func (r *SeriesRepository) ListByAuthor(ctx context.Context, authorID uint) ([]Series, error) {
+	var list []Series
+	if err := r.db.WithContext(ctx).
+		Where("author_id = ?", authorID).
+		Order("id desc").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *SeriesRepository) GetEpisodeByVideo(ctx context.Context, videoID uint) (*SeriesEpisode, error) {
+	var ep SeriesEpisode
+	if err := r.db.WithContext(ctx).Where("video_id = ?", videoID).First(&ep).Error; err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// AddEpisode 在 position 处插入视频（<=0 或超出末尾时追加），后面的剧集依次后移
+func (r *SeriesRepository) AddEpisode(ctx context.Context, seriesID, videoID uint, position int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住合集行，串行化同一合集的位置调整
+		var s Series
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, seriesID).Error; err != nil {
+			return err
+		}
+		if position <= 0 || int64(position) > s.EpisodesCount {
+			position = int(s.EpisodesCount) + 1
+		} else if err := tx.Model(&SeriesEpisode{}).
+			Where("series_id = ? AND position >= ?", seriesID, position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&SeriesEpisode{SeriesID: seriesID, VideoID: videoID, Position: position}).Error; err != nil {
+			if isDupKey(err) {
+				return errors.New("video already belongs to a series")
+			}
+			return err
+		}
+		return tx.Model(&Series{}).Where("id = ?", seriesID).
+			UpdateColumn("episodes_count", gorm.Expr("episodes_count + 1")).Error
+	})
+}
+
+// RemoveVideo 把视频移出所在合集并压缩后续位置，视频不在任何合集时返回 false
+func (r *SeriesRepository) RemoveVideo(ctx context.Context, videoID uint) (bool, error) {
+	removed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ep SeriesEpisode
+		if err := tx.Where("video_id = ?", videoID).First(&ep).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		var s Series
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, ep.SeriesID).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id = ?", ep.ID).Delete(&SeriesEpisode{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		removed = true
+		if err := tx.Model(&SeriesEpisode{}).
+			Where("series_id = ? AND position > ?", ep.SeriesID, ep.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Series{}).Where("id = ?", ep.SeriesID).
+			UpdateColumn("episodes_count", gorm.Expr("GREATEST(episodes_count - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+// Reorder 按 videoIDs 的顺序重写位置，videoIDs 必须恰好是合集内的全部视频
+func (r *SeriesRepository) Reorder(ctx context.Context, seriesID uint, videoIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var s Series
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, seriesID).Error; err != nil {
+			return err
+		}
+		var current []uint
+		if err := tx.Model(&SeriesEpisode{}).Where("series_id = ?", seriesID).Pluck("video_id", &current).Error; err != nil {
+			return err
+		}
+		if len(current) != len(videoIDs) {
+			return errors.New("video_ids must list every video in the series exactly once")
+		}
+		inSeries := make(map[uint]bool, len(current))
+		for _, id := range current {
+			inSeries[id] = true
+		}
+		for _, id := range videoIDs {
+			if !inSeries[id] {
+				return errors.New("video_ids must list every video in the series exactly once")
+			}
+			delete(inSeries, id)
+		}
+		for i, id := range videoIDs {
+			if err := tx.Model(&SeriesEpisode{}).
+				Where("series_id = ? AND video_id = ?", seriesID, id).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
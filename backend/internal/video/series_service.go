@@ -0,0 +1,197 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxSeriesTitleLen  = 128
+	maxSeriesPageLimit = 50
+)
+
+type SeriesService struct {
+	repo      *SeriesRepository
+	videoRepo *VideoRepository
+	videos    *VideoService
+	media     *MediaService
+}
+
+func NewSeriesService(repo *SeriesRepository, videoRepo *VideoRepository, videos *VideoService, media *MediaService) *SeriesService {
+	return &SeriesService{repo: repo, videoRepo: videoRepo, videos: videos, media: media}
+}
+
+func normalizeSeriesTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("title is required")
+	}
+	if utf8.RuneCountInString(title) > maxSeriesTitleLen {
+		return "", errors.New("title is too long")
+	}
+	return title, nil
+}
+
+func (s *SeriesService) Create(ctx context.Context, authorID uint, title, description string) (*Series, error) {
+	title, err := normalizeSeriesTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	series := &Series{AuthorID: authorID, Title: title, Description: strings.TrimSpace(description)}
+	if err := s.repo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (s *SeriesService) get(ctx context.Context, id uint) (*Series, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("series not found")
+		}
+		return nil, err
+	}
+	return series, nil
+}
+
+func (s *SeriesService) getOwned(ctx context.Context, id, authorID uint) (*Series, error) {
+	series, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if series.AuthorID != authorID {
+		return nil, errors.New("series not found")
+	}
+	return series, nil
+}
+
+func (s *SeriesService) Update(ctx context.Context, id, authorID uint, title, description *string) (*Series, error) {
+	series, err := s.getOwned(ctx, id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	updates := make(map[string]interface{})
+	if title != nil {
+		t, err := normalizeSeriesTitle(*title)
+		if err != nil {
+			return nil, err
+		}
+		updates["title"] = t
+		series.Title = t
+	}
+	if description != nil {
+		d := strings.TrimSpace(*description)
+		updates["description"] = d
+		series.Description = d
+	}
+	if len(updates) == 0 {
+		return series, nil
+	}
+	if err := s.repo.Update(ctx, id, updates); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (s *SeriesService) Delete(ctx context.Context, id, authorID uint) error {
+	if _, err := s.getOwned(ctx, id, authorID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// AddVideo 把自己的视频加入合集，position 从 1 开始，为 0 时追加到末尾
+func (s *SeriesService) AddVideo(ctx context.Context, authorID, seriesID, videoID uint, position int) error {
+	if _, err := s.getOwned(ctx, seriesID, authorID); err != nil {
+		return err
+	}
+	v, err := s.videoRepo.GetByID(ctx, videoID)
+	if err != nil || v.AuthorID != authorID {
+		return errors.New("video not found")
+	}
+	return s.repo.AddEpisode(ctx, seriesID, videoID, position)
+}
+
+func (s *SeriesService) RemoveVideo(ctx context.Context, authorID, seriesID, videoID uint) error {
+	if _, err := s.getOwned(ctx, seriesID, authorID); err != nil {
+		return err
+	}
+	ep, err := s.repo.GetEpisodeByVideo(ctx, videoID)
+	if err != nil || ep.SeriesID != seriesID {
+		return errors.New("video not in series")
+	}
+	_, err = s.repo.RemoveVideo(ctx, videoID)
+	return err
+}
+
+func (s *SeriesService) Reorder(ctx context.Context, authorID, seriesID uint, videoIDs []uint) error {
+	if _, err := s.getOwned(ctx, seriesID, authorID); err != nil {
+		return err
+	}
+	return s.repo.Reorder(ctx, seriesID, videoIDs)
+}
+
+// Get 返回合集和观众可见的剧集，按剧集顺序分页
+func (s *SeriesService) Get(ctx context.Context, id, viewerAccountID uint, afterPosition, limit int) (*SeriesDetailResponse, error) {
+	series, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxSeriesPageLimit {
+		limit = maxSeriesPageLimit
+	}
+	visibilities, err := s.videos.AuthorVisibilities(ctx, series.AuthorID, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	videos, positions, err := s.videoRepo.ListSeriesVideos(ctx, series.ID, series.AuthorID, visibilities, afterPosition, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range videos {
+		s.media.FillURLs(&videos[i])
+	}
+	resp := &SeriesDetailResponse{Series: series, Videos: videos, HasMore: len(positions) == limit}
+	if len(positions) > 0 {
+		resp.NextAfterPosition = positions[len(positions)-1]
+	}
+	return resp, nil
+}
+
+func (s *SeriesService) ListByAuthor(ctx context.Context, authorID uint) ([]Series, error) {
+	return s.repo.ListByAuthor(ctx, authorID)
+}
+
+// EpisodeInfo 计算视频在合集中的集数和观众可见的上一集/下一集，不在合集中时返回 nil
+func (s *SeriesService) EpisodeInfo(ctx context.Context, video *Video, viewerAccountID uint) (*SeriesInfo, error) {
+	ep, err := s.repo.GetEpisodeByVideo(ctx, video.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	series, err := s.repo.GetByID(ctx, ep.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	// 只在观众能看到的剧集里找上一集/下一集，集数即合集内位置（位置从 1 开始连续）
+	visibilities, err := s.videos.AuthorVisibilities(ctx, series.AuthorID, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	info := &SeriesInfo{ID: series.ID, Title: series.Title, Episode: ep.Position, EpisodesCount: int(series.EpisodesCount)}
+	info.Prev, info.Next, err = s.videoRepo.SeriesNeighbors(ctx, series.ID, series.AuthorID, visibilities, ep.Position)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,93 @@
+package video
+
+import "time"
+
+const (
+	UploadStatusUploading = "uploading"
+	UploadStatusCompleted = "completed"
+)
+
+// UploadSession 分片上传会话，分片以对象形式存放在存储后端的 chunks/ 前缀下，完成后合并为视频文件
+type UploadSession struct {
+	ID          string    `gorm:"primaryKey;type:varchar(32)" json:"session_id"`
+	AccountID   uint      `gorm:"index;not null" json:"account_id"`
+	Filename    string    `gorm:"type:varchar(255);not null" json:"filename"`
+	FileSize    int64     `gorm:"not null" json:"file_size"`
+	ChunkSize   int64     `gorm:"not null" json:"chunk_size"`
+	TotalChunks int       `gorm:"not null" json:"total_chunks"`
+	FileSHA256  string    `gorm:"type:varchar(64)" json:"sha256,omitempty"`
+	Status      string    `gorm:"type:varchar(16);not null;default:uploading" json:"status"`
+	PlayKey     string    `gorm:"type:varchar(255)" json:"play_key,omitempty"`
+	ExpiresAt   time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type UploadChunk struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	SessionID string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_upload_chunk_session_index" json:"session_id"`
+	Index     int       `gorm:"column:chunk_index;not null;uniqueIndex:idx_upload_chunk_session_index" json:"index"`
+	Size      int64     `gorm:"not null" json:"size"`
+	SHA256    string    `gorm:"type:varchar(64);not null" json:"sha256"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type InitUploadRequest struct {
+	Filename  string `json:"filename"`
+	FileSize  int64  `json:"file_size"`
+	ChunkSize int64  `json:"chunk_size"`
+	SHA256    string `json:"sha256"`
+}
+
+type InitUploadResponse struct {
+	SessionID   string `json:"session_id"`
+	ChunkSize   int64  `json:"chunk_size"`
+	TotalChunks int    `json:"total_chunks"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+type UploadSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type UploadStatusResponse struct {
+	SessionID   string `json:"session_id"`
+	Status      string `json:"status"`
+	TotalChunks int    `json:"total_chunks"`
+	Received    []int  `json:"received"`
+	ExpiresAt   int64  `json:"expires_at"`
+	PlayKey     string `json:"play_key,omitempty"`
+	PlayURL     string `json:"play_url,omitempty"`
+}
+
+type PresignUploadRequest struct {
+	// video | cover
+	Kind     string `json:"kind"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+type PresignUploadResponse struct {
+	// 上传完成后用于 publish 的 play_key/cover_key
+	Key         string `json:"key"`
+	Method      string `json:"method"`
+	UploadURL   string `json:"upload_url"`
+	ContentType string `json:"content_type"`
+	// 上传时必须原样携带的请求头；地址只能上传一次声明大小的文件，不能覆盖已有对象
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+// QuotaResponse 配额与当天用量，limit 为 0 表示不限制，remaining 为 -1 表示不限制
+type QuotaResponse struct {
+	StorageLimit       int64 `json:"storage_limit"`
+	StorageUsed        int64 `json:"storage_used"`
+	StorageRemaining   int64 `json:"storage_remaining"`
+	DailyUploadLimit   int64 `json:"daily_upload_limit"`
+	UploadsToday       int64 `json:"uploads_today"`
+	UploadsRemaining   int64 `json:"uploads_remaining"`
+	DailyPublishLimit  int64 `json:"daily_publish_limit"`
+	PublishesToday     int64 `json:"publishes_today"`
+	PublishesRemaining int64 `json:"publishes_remaining"`
+	// 每日次数重置时间（unix 秒）
+	ResetAt int64 `json:"reset_at"`
+}
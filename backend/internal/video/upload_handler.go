@@ -0,0 +1,139 @@
+package video
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"feedsystem_video_go/internal/middleware/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UploadHandler struct {
+	service *UploadService
+	quota   *QuotaService
+}
+
+func NewUploadHandler(service *UploadService, quota *QuotaService) *UploadHandler {
+	return &UploadHandler{service: service, quota: quota}
+}
+
+func (h *UploadHandler) Init(c *gin.Context) {
+	var req InitUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 分片上传在创建会话时占用配额
+	release, err := h.quota.ReserveUpload(c.Request.Context(), accountID, req.FileSize)
+	if err != nil {
+		writeQuotaError(c, err)
+		return
+	}
+	session, err := h.service.Init(c.Request.Context(), accountID, req.Filename, req.FileSize, req.ChunkSize, req.SHA256)
+	if err != nil {
+		release()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, InitUploadResponse{
+		SessionID:   session.ID,
+		ChunkSize:   session.ChunkSize,
+		TotalChunks: session.TotalChunks,
+		ExpiresAt:   session.ExpiresAt.Unix(),
+	})
+}
+
+// Chunk multipart 表单：session_id、index、sha256、file
+func (h *UploadHandler) Chunk(c *gin.Context) {
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	index, err := strconv.Atoi(c.PostForm("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid index"})
+		return
+	}
+	f, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	src, err := f.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
+
+	if err := h.service.PutChunk(c.Request.Context(), accountID, c.PostForm("session_id"), index, c.PostForm("sha256"), src); err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "chunk received", "index": index})
+}
+
+func (h *UploadHandler) Status(c *gin.Context) {
+	var req UploadSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := h.service.Status(c.Request.Context(), accountID, req.SessionID)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *UploadHandler) Complete(c *gin.Context) {
+	var req UploadSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := h.service.Complete(c.Request.Context(), accountID, req.SessionID)
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+	u := h.service.media.SignURL(key)
+	c.JSON(http.StatusOK, gin.H{
+		"key":      key,
+		"play_key": key,
+		"url":      u,
+		"play_url": u,
+	})
+}
+
+func writeUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUploadSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUploadSessionExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUploadIncomplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,80 @@
+package video
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+func (r *UploadRepository) CreateSession(ctx context.Context, session *UploadSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *UploadRepository) GetSession(ctx context.Context, id string) (*UploadSession, error) {
+	var session UploadSession
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UploadRepository) CompleteSession(ctx context.Context, id string, playKey string) error {
+	return r.db.WithContext(ctx).Model(&UploadSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": UploadStatusCompleted, "play_key": playKey}).Error
+}
+
+// 同一分片重复上传时覆盖旧记录
+func (r *UploadRepository) SaveChunk(ctx context.Context, chunk *UploadChunk) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ? AND chunk_index = ?", chunk.SessionID, chunk.Index).
+			Delete(&UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(chunk).Error
+	})
+}
+
+func (r *UploadRepository) ListChunks(ctx context.Context, sessionID string) ([]UploadChunk, error) {
+	var chunks []UploadChunk
+	if err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("chunk_index asc").
+		Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+func (r *UploadRepository) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]UploadSession, error) {
+	var sessions []UploadSession
+	if err := r.db.WithContext(ctx).
+		Where("expires_at < ?", now).
+		Limit(limit).
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *UploadRepository) DeleteChunks(ctx context.Context, sessionID string) error {
+	return r.db.WithContext(ctx).Where("session_id = ?", sessionID).Delete(&UploadChunk{}).Error
+}
+
+func (r *UploadRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", sessionID).Delete(&UploadSession{}).Error
+	})
+}
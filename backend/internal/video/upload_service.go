@@ -0,0 +1,304 @@
+package video
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"feedsystem_video_go/internal/storage"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 分片以对象形式写入存储后端，多实例部署时任意实例都能接收分片和合并
+const uploadChunkPrefix = "chunks/"
+
+const (
+	maxChunkedUploadSize = 2 << 30
+	minChunkSize         = 1 << 20
+	maxChunkSize         = 16 << 20
+	defaultChunkSize     = 5 << 20
+	uploadSessionTTL     = 24 * time.Hour
+)
+
+var (
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+	ErrUploadSessionExpired  = errors.New("upload session expired")
+	ErrUploadIncomplete      = errors.New("upload is incomplete")
+	ErrChunkChecksum         = errors.New("chunk checksum mismatch")
+)
+
+type UploadService struct {
+	repo  *UploadRepository
+	media *MediaService
+}
+
+func NewUploadService(repo *UploadRepository, media *MediaService) *UploadService {
+	return &UploadService{repo: repo, media: media}
+}
+
+func (s *UploadService) Init(ctx context.Context, accountID uint, filename string, fileSize, chunkSize int64, fileSHA256 string) (*UploadSession, error) {
+	filename = strings.TrimSpace(filename)
+	if strings.ToLower(filepath.Ext(filename)) != ".mp4" {
+		return nil, errors.New("only .mp4 is allowed")
+	}
+	if fileSize <= 0 || fileSize > maxChunkedUploadSize {
+		return nil, errors.New("invalid file size")
+	}
+	if chunkSize == 0 {
+		chunkSize = defaultChunkSize
+	}
+	if chunkSize < minChunkSize || chunkSize > maxChunkSize {
+		return nil, fmt.Errorf("chunk size must be between %d and %d", minChunkSize, maxChunkSize)
+	}
+	fileSHA256 = strings.ToLower(strings.TrimSpace(fileSHA256))
+	if fileSHA256 != "" && !isHexSHA256(fileSHA256) {
+		return nil, errors.New("invalid sha256")
+	}
+
+	session := &UploadSession{
+		ID:          randHex(16),
+		AccountID:   accountID,
+		Filename:    filename,
+		FileSize:    fileSize,
+		ChunkSize:   chunkSize,
+		TotalChunks: int((fileSize + chunkSize - 1) / chunkSize),
+		FileSHA256:  fileSHA256,
+		Status:      UploadStatusUploading,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// PutChunk 写入一个分片并校验 SHA-256，同一分片可重复上传（断点续传）
+func (s *UploadService) PutChunk(ctx context.Context, accountID uint, sessionID string, index int, checksum string, r io.Reader) error {
+	session, err := s.activeSession(ctx, accountID, sessionID)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= session.TotalChunks {
+		return errors.New("invalid chunk index")
+	}
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if !isHexSHA256(checksum) {
+		return errors.New("invalid sha256")
+	}
+	expected := session.ChunkSize
+	if index == session.TotalChunks-1 {
+		expected = session.FileSize - session.ChunkSize*int64(session.TotalChunks-1)
+	}
+
+	// 先在本地临时文件中校验，避免坏分片覆盖后端中已接收的同序号分片
+	tmp, err := os.CreateTemp("", "chunk-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, expected+1))
+	if err != nil {
+		return err
+	}
+	if n != expected {
+		return fmt.Errorf("chunk %d size mismatch: expected %d, got %d", index, expected, n)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != checksum {
+		return ErrChunkChecksum
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.media.store.Put(ctx, chunkKey(session.ID, index), tmp, n, "application/octet-stream"); err != nil {
+		return err
+	}
+	return s.repo.SaveChunk(ctx, &UploadChunk{
+		SessionID: session.ID,
+		Index:     index,
+		Size:      n,
+		SHA256:    checksum,
+	})
+}
+
+func (s *UploadService) Status(ctx context.Context, accountID uint, sessionID string) (UploadStatusResponse, error) {
+	session, err := s.getSession(ctx, accountID, sessionID)
+	if err != nil {
+		return UploadStatusResponse{}, err
+	}
+	chunks, err := s.repo.ListChunks(ctx, session.ID)
+	if err != nil {
+		return UploadStatusResponse{}, err
+	}
+	received := make([]int, 0, len(chunks))
+	for _, c := range chunks {
+		received = append(received, c.Index)
+	}
+	return UploadStatusResponse{
+		SessionID:   session.ID,
+		Status:      session.Status,
+		TotalChunks: session.TotalChunks,
+		Received:    received,
+		ExpiresAt:   session.ExpiresAt.Unix(),
+		PlayKey:     session.PlayKey,
+		PlayURL:     s.media.SignURL(session.PlayKey),
+	}, nil
+}
+
+// Complete 按顺序合并所有分片，经内容校验后写入存储后端，返回存储 key
+func (s *UploadService) Complete(ctx context.Context, accountID uint, sessionID string) (string, error) {
+	session, err := s.getSession(ctx, accountID, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if session.Status == UploadStatusCompleted {
+		return session.PlayKey, nil
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return "", ErrUploadSessionExpired
+	}
+	chunks, err := s.repo.ListChunks(ctx, session.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(chunks) != session.TotalChunks {
+		return "", ErrUploadIncomplete
+	}
+
+	// 从存储后端按序取回分片，在本地临时文件中合并并校验，再整体写入存储后端
+	tmp, err := os.CreateTemp("", "assemble-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	w := io.MultiWriter(tmp, h)
+	var written int64
+	for i, c := range chunks {
+		if c.Index != i {
+			return "", ErrUploadIncomplete
+		}
+		n, err := s.appendChunk(ctx, w, session.ID, i)
+		if err != nil {
+			return "", err
+		}
+		if n != c.Size {
+			return "", fmt.Errorf("chunk %d size mismatch: expected %d, got %d", i, c.Size, n)
+		}
+		written += n
+	}
+	if written != session.FileSize {
+		return "", fmt.Errorf("assembled size mismatch: expected %d, got %d", session.FileSize, written)
+	}
+	if session.FileSHA256 != "" && hex.EncodeToString(h.Sum(nil)) != session.FileSHA256 {
+		return "", errors.New("file checksum mismatch")
+	}
+	obj, err := s.media.Upload(ctx, MediaKindVideo, accountID, session.Filename, tmp, written)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.CompleteSession(ctx, session.ID, obj.Key); err != nil {
+		return "", err
+	}
+	if err := s.repo.DeleteChunks(ctx, session.ID); err != nil {
+		log.Printf("upload service: failed to delete chunk records: %v", err)
+	}
+	if err := s.removeChunks(ctx, session); err != nil {
+		log.Printf("upload service: failed to remove chunks: %v", err)
+	}
+	return obj.Key, nil
+}
+
+// CleanupExpired 删除过期会话及其残留分片，返回清理的会话数
+func (s *UploadService) CleanupExpired(ctx context.Context) (int, error) {
+	cleaned := 0
+	for {
+		sessions, err := s.repo.ListExpiredSessions(ctx, time.Now(), 100)
+		if err != nil {
+			return cleaned, err
+		}
+		if len(sessions) == 0 {
+			return cleaned, nil
+		}
+		for _, session := range sessions {
+			if err := s.removeChunks(ctx, &session); err != nil {
+				return cleaned, err
+			}
+			if err := s.repo.DeleteSession(ctx, session.ID); err != nil {
+				return cleaned, err
+			}
+			cleaned++
+		}
+	}
+}
+
+func (s *UploadService) activeSession(ctx context.Context, accountID uint, sessionID string) (*UploadSession, error) {
+	session, err := s.getSession(ctx, accountID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != UploadStatusUploading {
+		return nil, errors.New("upload session already completed")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrUploadSessionExpired
+	}
+	return session, nil
+}
+
+func (s *UploadService) getSession(ctx context.Context, accountID uint, sessionID string) (*UploadSession, error) {
+	if sessionID == "" {
+		return nil, ErrUploadSessionNotFound
+	}
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, ErrUploadSessionNotFound
+	}
+	if session.AccountID != accountID {
+		return nil, ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *UploadService) appendChunk(ctx context.Context, w io.Writer, sessionID string, index int) (int64, error) {
+	obj, err := s.media.store.Open(ctx, chunkKey(sessionID, index))
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Close()
+	return io.Copy(w, obj)
+}
+
+// removeChunks 按序号删除会话的分片对象，不存在的分片视为已删除
+func (s *UploadService) removeChunks(ctx context.Context, session *UploadSession) error {
+	for i := 0; i < session.TotalChunks; i++ {
+		if err := s.media.store.Delete(ctx, chunkKey(session.ID, i)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func chunkKey(sessionID string, index int) string {
+	return uploadChunkPrefix + sessionID + "/" + strconv.Itoa(index) + ".part"
+}
+
+func isHexSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/social"
+)
+
+// VideoCleaner 处理视频发布/删除/更新后的善后工作，由 VideoWorker 异步调用，MQ 不可用时同步调用
+type VideoCleaner struct {
+	videos   *VideoRepository
+	likes    *LikeRepository
+	comments *CommentRepository
+	social   *social.SocialRepository
+	cache    *rediscache.Client
+	media    *MediaService
+	packager *HLSPackager
+	series   *SeriesRepository
+}
+
+func NewVideoCleaner(videos *VideoRepository, likes *LikeRepository, comments *CommentRepository, socialRepo *social.SocialRepository, cache *rediscache.Client, media *MediaService, packager *HLSPackager, series *SeriesRepository) *VideoCleaner {
+	return &VideoCleaner{videos: videos, likes: likes, comments: comments, social: socialRepo, cache: cache, media: media, packager: packager, series: series}
+}
+
+// OnPublished 视频上线：更新作者作品数，失效详情与最新流缓存，并向作者的关注者扩散（失效其关注流缓存）
+func (c *VideoCleaner) OnPublished(ctx context.Context, videoID, authorID uint) error {
+	if videoID == 0 {
+		return nil
+	}
+	if err := c.videos.RefreshAuthorVideosCount(ctx, authorID); err != nil {
+		return err
+	}
+	if c.cache == nil {
+		return nil
+	}
+	_ = c.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", videoID))
+
+	opCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	if _, err := c.cache.DelByPattern(opCtx, "feed:listLatest:*"); err != nil {
+		log.Printf("video cleaner: failed to invalidate latest feed: %v", err)
+	}
+	if authorID != 0 {
+		pattern := fmt.Sprintf("feed:listByAuthor:author=%d:*", authorID)
+		if _, err := c.cache.DelByPattern(opCtx, pattern); err != nil {
+			log.Printf("video cleaner: failed to invalidate %s: %v", pattern, err)
+		}
+	}
+	if c.social == nil || authorID == 0 {
+		return nil
+	}
+	followerIDs, err := c.social.ListFollowerIDs(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	for _, followerID := range followerIDs {
+		pattern := fmt.Sprintf("feed:listByFollowing:*:accountID=%d:*", followerID)
+		if _, err := c.cache.DelByPattern(opCtx, pattern); err != nil {
+			log.Printf("video cleaner: failed to invalidate %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (c *VideoCleaner) OnDeleted(ctx context.Context, videoID, authorID uint, playKey, coverKey string) error {
+	if videoID == 0 {
+		return nil
+	}
+	RemoveFromPopularityCache(ctx, c.cache, videoID)
+	c.invalidateFeeds(ctx)
+
+	if err := c.videos.RefreshAuthorVideosCount(ctx, authorID); err != nil {
+		return err
+	}
+	if err := c.likes.DeleteByVideoID(ctx, videoID, authorID); err != nil {
+		return err
+	}
+	if err := c.comments.DeleteByVideoID(ctx, videoID); err != nil {
+		return err
+	}
+	if c.series != nil {
+		if _, err := c.series.RemoveVideo(ctx, videoID); err != nil {
+			return err
+		}
+	}
+	c.releaseMedia(ctx, playKey)
+	c.releaseMedia(ctx, coverKey)
+	return nil
+}
+
+func (c *VideoCleaner) OnUpdated(ctx context.Context, videoID uint, coverKey, oldCoverKey string) error {
+	if videoID == 0 {
+		return nil
+	}
+	if c.cache != nil {
+		_ = c.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", videoID))
+	}
+	c.invalidateFeeds(ctx)
+	if oldCoverKey != "" && oldCoverKey != coverKey {
+		c.releaseMedia(ctx, oldCoverKey)
+	}
+	return nil
+}
+
+// OnUploaded 打包视频文件为 HLS，完成后失效相关视频的详情和流缓存，使 play_url 切换到播放列表
+func (c *VideoCleaner) OnUploaded(ctx context.Context, playKey string) error {
+	if c.packager == nil || playKey == "" {
+		return nil
+	}
+	ids, err := c.packager.Package(ctx, playKey)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 || c.cache == nil {
+		return nil
+	}
+	for _, id := range ids {
+		_ = c.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+	}
+	c.invalidateFeeds(ctx)
+	return nil
+}
+
+// 失效匿名最新流缓存和各关注者的关注流缓存
+func (c *VideoCleaner) invalidateFeeds(ctx context.Context) {
+	if c.cache == nil {
+		return
+	}
+	opCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	for _, pattern := range []string{"feed:listLatest:*", "feed:listByFollowing:*", "feed:listByAuthor:*"} {
+		if _, err := c.cache.DelByPattern(opCtx, pattern); err != nil {
+			log.Printf("video cleaner: failed to invalidate %s: %v", pattern, err)
+		}
+	}
+}
+
+// 释放视频对上传文件的引用，引用归零时由 MediaService 回收文件
+func (c *VideoCleaner) releaseMedia(ctx context.Context, key string) {
+	if c.media == nil {
+		return
+	}
+	if err := c.media.Release(ctx, key); err != nil {
+		log.Printf("video cleaner: failed to release %s: %v", key, err)
+	}
+}
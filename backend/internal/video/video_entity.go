@@ -1,33 +1,122 @@
 package video
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	VisibilityPublic    = "public"
+	VisibilityUnlisted  = "unlisted"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+)
+
+func IsValidVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityFollowers, VisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+)
+
+// 视频/评论被隐藏的原因：举报达到阈值自动隐藏的可以在误报结案时恢复，人工下架的不会被自动恢复
+const (
+	HiddenByReport = "report"
+	HiddenByAdmin  = "admin"
+)
 
 type Video struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	AuthorID    uint      `gorm:"index;not null" json:"author_id"`
-	Username    string    `gorm:"type:varchar(255);not null" json:"username"`
-	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
-	Description string    `gorm:"type:varchar(255);" json:"description,omitempty"`
-	PlayURL     string    `gorm:"type:varchar(255);not null" json:"play_url"`
-	CoverURL    string    `gorm:"type:varchar(255);not null" json:"cover_url"`
-	CreateTime  time.Time `gorm:"autoCreateTime" json:"create_time"`
-	LikesCount  int64     `gorm:"column:likes_count;not null;default:0" json:"likes_count"`
-	Popularity  int64     `gorm:"column:popularity;not null;default:0" json:"popularity"`
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	AuthorID    uint   `gorm:"index;not null" json:"author_id"`
+	Username    string `gorm:"type:varchar(255);not null" json:"username"`
+	Title       string `gorm:"type:varchar(255);not null" json:"title"`
+	Description string `gorm:"type:varchar(255);" json:"description,omitempty"`
+	PlayKey     string `gorm:"type:varchar(255);not null;index" json:"play_key"`
+	CoverKey    string `gorm:"type:varchar(255);not null;index" json:"cover_key"`
+	PlayURL     string `gorm:"-" json:"play_url"`
+	// HLS 打包完成后 play_url 指向播放列表，mp4_url 始终是原始 MP4
+	MP4URL    string `gorm:"-" json:"mp4_url,omitempty"`
+	HLSStatus string `gorm:"column:hls_status;type:varchar(16);not null;default:''" json:"hls_status,omitempty"`
+	HLSKey    string `gorm:"column:hls_key;type:varchar(255)" json:"hls_key,omitempty"`
+	CoverURL  string `gorm:"-" json:"cover_url"`
+	// 封面缩略图 key，来自封面 MediaObject；CoverVariants 为响应时签名的地址
+	CoverVariantKeys map[string]string `gorm:"serializer:json;type:text" json:"cover_variant_keys,omitempty"`
+	CoverVariants    map[string]string `gorm:"-" json:"cover_variants,omitempty"`
+	CreateTime       time.Time         `gorm:"autoCreateTime" json:"create_time"`
+	LikesCount       int64             `gorm:"column:likes_count;not null;default:0" json:"likes_count"`
+	CollectCount     int64             `gorm:"column:collect_count;not null;default:0" json:"collect_count"`
+	Popularity       int64             `gorm:"column:popularity;not null;default:0" json:"popularity"`
+	ViewsCount       int64             `gorm:"column:views_count;not null;default:0" json:"views_count"`
+	CompletionsCount int64             `gorm:"column:completions_count;not null;default:0" json:"completions_count"`
+	WatchTimeMs      int64             `gorm:"column:watch_time_ms;not null;default:0" json:"watch_time_ms"`
+	Visibility       string            `gorm:"type:varchar(16);not null;default:public;index" json:"visibility"`
+	Status           string            `gorm:"type:varchar(16);not null;default:published;index" json:"status"`
+	PublishAt        *time.Time        `gorm:"index" json:"publish_at,omitempty"`
+	Hidden           bool              `gorm:"not null;default:false;index" json:"hidden,omitempty"`
+	HiddenReason     string            `gorm:"type:varchar(16);not null;default:''" json:"hidden_reason,omitempty"`
+	DurationMs       int64             `gorm:"not null;default:0" json:"duration_ms,omitempty"`
+	Width            int               `gorm:"not null;default:0" json:"width,omitempty"`
+	Height           int               `gorm:"not null;default:0" json:"height,omitempty"`
+	VideoCodec       string            `gorm:"type:varchar(16)" json:"video_codec,omitempty"`
+	AudioCodec       string            `gorm:"type:varchar(16)" json:"audio_codec,omitempty"`
+	DeletedAt        gorm.DeletedAt    `gorm:"index" json:"-"`
+	// 当前登录观众的续播位置(毫秒)
+	ResumePosition int64 `gorm:"-" json:"resume_position,omitempty"`
+	// 视频所属合集及上一集/下一集，仅详情接口返回
+	Series *SeriesInfo `gorm:"-" json:"series,omitempty"`
 }
 
+// play_key/cover_key 为上传接口返回的存储 key；兼容直接传上传返回的 play_url/cover_url
 type PublishVideoRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	PlayKey     string `json:"play_key"`
+	CoverKey    string `json:"cover_key"`
 	PlayURL     string `json:"play_url"`
 	CoverURL    string `json:"cover_url"`
+	Visibility  string `json:"visibility"`
 }
 
 type DeleteVideoRequest struct {
 	ID uint `json:"id"`
 }
 
-type ListByAuthorIDRequest struct {
-	AuthorID uint `json:"author_id"`
+type UpdateVideoRequest struct {
+	ID          uint    `json:"id"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	CoverKey    *string `json:"cover_key,omitempty"`
+	CoverURL    *string `json:"cover_url,omitempty"`
+	Visibility  *string `json:"visibility,omitempty"`
+}
+
+type SaveDraftRequest struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PlayKey     string `json:"play_key"`
+	CoverKey    string `json:"cover_key"`
+	PlayURL     string `json:"play_url"`
+	CoverURL    string `json:"cover_url"`
+	Visibility  string `json:"visibility"`
+}
+
+type PublishDraftRequest struct {
+	ID        uint  `json:"id"`
+	PublishAt int64 `json:"publish_at"` // 0 表示立即发布
+}
+
+type DraftIDRequest struct {
+	ID uint `json:"id"`
 }
 
 type GetDetailRequest struct {
@@ -3,16 +3,18 @@ package video
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/media"
 	"feedsystem_video_go/internal/middleware/jwt"
+	"feedsystem_video_go/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,10 +22,19 @@ import (
 type VideoHandler struct {
 	service        *VideoService
 	accountService *account.AccountService
+	media          *MediaService
+	store          storage.Backend
+	presignTTL     time.Duration
+	quota          *QuotaService
+	history        *HistoryService
+	series         *SeriesService
 }
 
-func NewVideoHandler(service *VideoService, accountService *account.AccountService) *VideoHandler {
-	return &VideoHandler{service: service, accountService: accountService}
+func NewVideoHandler(service *VideoService, accountService *account.AccountService, media *MediaService, store storage.Backend, presignTTL time.Duration, quota *QuotaService, history *HistoryService, series *SeriesService) *VideoHandler {
+	if presignTTL <= 0 {
+		presignTTL = 15 * time.Minute
+	}
+	return &VideoHandler{service: service, accountService: accountService, media: media, store: store, presignTTL: presignTTL, quota: quota, history: history, series: series}
 }
 
 func (vh *VideoHandler) PublishVideo(c *gin.Context) {
@@ -48,68 +59,163 @@ func (vh *VideoHandler) PublishVideo(c *gin.Context) {
 		Username:    user.Username,
 		Title:       req.Title,
 		Description: req.Description,
-		PlayURL:     req.PlayURL,
-		CoverURL:    req.CoverURL,
+		PlayKey:     firstNonEmpty(req.PlayKey, req.PlayURL),
+		CoverKey:    firstNonEmpty(req.CoverKey, req.CoverURL),
+		Visibility:  req.Visibility,
 		CreateTime:  time.Now(),
 	}
+	release, err := vh.quota.ReservePublish(c.Request.Context(), authorId)
+	if err != nil {
+		writeQuotaError(c, err)
+		return
+	}
 	if err := vh.service.Publish(c.Request.Context(), video); err != nil {
+		release()
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	vh.media.FillURLs(video)
 	c.JSON(200, video)
 }
 
-func (vh *VideoHandler) UploadVideo(c *gin.Context) {
+func (vh *VideoHandler) SaveDraft(c *gin.Context) {
+	var req SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-
-	f, err := c.FormFile("file")
+	user, err := vh.accountService.FindByID(c.Request.Context(), authorId)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-
-	const maxSize = 200 << 20
-	if f.Size <= 0 || f.Size > maxSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
+	draft, err := vh.service.SaveDraft(c.Request.Context(), &Video{
+		ID:          req.ID,
+		AuthorID:    authorId,
+		Username:    user.Username,
+		Title:       req.Title,
+		Description: req.Description,
+		PlayKey:     firstNonEmpty(req.PlayKey, req.PlayURL),
+		CoverKey:    firstNonEmpty(req.CoverKey, req.CoverURL),
+		Visibility:  req.Visibility,
+		CreateTime:  time.Now(),
+	})
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	vh.media.FillURLs(draft)
+	c.JSON(200, draft)
+}
 
-	ext := strings.ToLower(filepath.Ext(f.Filename))
-	if ext != ".mp4" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "only .mp4 is allowed"})
+func (vh *VideoHandler) ListDrafts(c *gin.Context) {
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	drafts, err := vh.service.ListDrafts(c.Request.Context(), authorId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	for i := range drafts {
+		vh.media.FillURLs(&drafts[i])
+	}
+	c.JSON(200, drafts)
+}
 
-	date := time.Now().Format("20060102")
-	relDir := filepath.Join("videos", fmt.Sprintf("%d", authorId), date)
-	root := filepath.Join(".run", "uploads")
-	absDir := filepath.Join(root, relDir)
-	if err := os.MkdirAll(absDir, 0o755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (vh *VideoHandler) PublishDraft(c *gin.Context) {
+	var req PublishDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var publishAt time.Time
+	if req.PublishAt > 0 {
+		publishAt = time.Unix(req.PublishAt, 0)
+	}
+	release, err := vh.quota.ReservePublish(c.Request.Context(), authorId)
+	if err != nil {
+		writeQuotaError(c, err)
+		return
+	}
+	video, err := vh.service.PublishDraft(c.Request.Context(), req.ID, authorId, publishAt)
+	if err != nil {
+		release()
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	vh.media.FillURLs(video)
+	c.JSON(200, video)
+}
 
-	filename := randHex(16) + ext
-	absPath := filepath.Join(absDir, filename)
+func (vh *VideoHandler) UnscheduleDraft(c *gin.Context) {
+	var req DraftIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := vh.service.UnscheduleDraft(c.Request.Context(), req.ID, authorId); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"message": "schedule canceled"})
+}
 
-	if err := c.SaveUploadedFile(f, absPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (vh *VideoHandler) DeleteDraft(c *gin.Context) {
+	var req DraftIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := vh.service.DeleteDraft(c.Request.Context(), req.ID, authorId); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(200, gin.H{"message": "draft deleted"})
+}
 
-	urlPath := path.Join("/static", "videos", fmt.Sprintf("%d", authorId), date, filename)
+const (
+	maxVideoUploadSize = 200 << 20
+	maxCoverUploadSize = 10 << 20
+	// 直传地址只需覆盖一次上传，有效期不超过 15 分钟
+	maxPresignPutTTL = 15 * time.Minute
+)
 
-	c.JSON(http.StatusOK, gin.H{
-		"url":      buildAbsoluteURL(c, urlPath),
-		"play_url": buildAbsoluteURL(c, urlPath),
-	})
+func (vh *VideoHandler) UploadVideo(c *gin.Context) {
+	vh.upload(c, MediaKindVideo, maxVideoUploadSize, "play")
 }
 
 func (vh *VideoHandler) UploadCover(c *gin.Context) {
+	vh.upload(c, MediaKindCover, maxCoverUploadSize, "cover")
+}
+
+func (vh *VideoHandler) upload(c *gin.Context, kind string, maxSize int64, field string) {
 	authorId, err := jwt.GetAccountID(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -121,46 +227,189 @@ func (vh *VideoHandler) UploadCover(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
 		return
 	}
-
-	const maxSize = 10 << 20
 	if f.Size <= 0 || f.Size > maxSize {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
 		return
 	}
+	release, err := vh.quota.ReserveUpload(c.Request.Context(), authorId, f.Size)
+	if err != nil {
+		writeQuotaError(c, err)
+		return
+	}
+	src, err := f.Open()
+	if err != nil {
+		release()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
 
-	ext := strings.ToLower(filepath.Ext(f.Filename))
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".webp":
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "only .jpg/.jpeg/.png/.webp is allowed"})
+	obj, err := vh.media.Upload(c.Request.Context(), kind, authorId, f.Filename, src, f.Size)
+	if err != nil {
+		release()
+		if errors.Is(err, media.ErrInvalidMP4) || errors.Is(err, media.ErrInvalidImage) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	date := time.Now().Format("20060102")
-	relDir := filepath.Join("covers", fmt.Sprintf("%d", authorId), date)
-	root := filepath.Join(".run", "uploads")
-	absDir := filepath.Join(root, relDir)
-	if err := os.MkdirAll(absDir, 0o755); err != nil {
+	u := vh.media.SignURL(obj.Key)
+	resp := gin.H{
+		"key":          obj.Key,
+		field + "_key": obj.Key,
+		"url":          u,
+		field + "_url": u,
+		"width":        obj.Width,
+		"height":       obj.Height,
+	}
+	if variants := vh.media.SignVariants(obj.Variants); variants != nil {
+		resp["variants"] = variants
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PresignUpload 生成客户端直传存储后端的临时 PUT 地址
+func (vh *VideoHandler) PresignUpload(c *gin.Context) {
+	var req PresignUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var kind string
+	var maxSize int64
+	switch req.Kind {
+	case "video":
+		kind, maxSize = "videos", maxVideoUploadSize
+	case "cover":
+		kind, maxSize = "covers", maxCoverUploadSize
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be video or cover"})
+		return
+	}
+	if req.Size <= 0 || req.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file size"})
+		return
+	}
+	key, contentType, err := uploadKey(kind, authorId, req.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	release, err := vh.quota.ReserveUpload(c.Request.Context(), authorId, req.Size)
+	if err != nil {
+		writeQuotaError(c, err)
+		return
+	}
+	ttl := min(vh.presignTTL, maxPresignPutTTL)
+	uploadURL, err := vh.store.PresignPut(c.Request.Context(), key, contentType, req.Size, ttl)
+	if err != nil {
+		release()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, PresignUploadResponse{
+		Key:         key,
+		Method:      http.MethodPut,
+		UploadURL:   absoluteURL(c, uploadURL),
+		ContentType: contentType,
+		Headers:     storage.PresignPutHeaders(contentType),
+		ExpiresAt:   time.Now().Add(ttl).Unix(),
+	})
+}
 
-	filename := randHex(16) + ext
-	absPath := filepath.Join(absDir, filename)
-
-	if err := c.SaveUploadedFile(f, absPath); err != nil {
+// Quota 当前账号的上传配额和当天用量
+func (vh *VideoHandler) Quota(c *gin.Context) {
+	accountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := vh.quota.Usage(c.Request.Context(), accountID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	urlPath := path.Join("/static", "covers", fmt.Sprintf("%d", authorId), date, filename)
+func writeQuotaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrStorageQuotaExceeded):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrDailyUploadLimit), errors.Is(err, ErrDailyPublishLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
 
+// PresignDownload 为有权观看的视频生成临时下载地址
+func (vh *VideoHandler) PresignDownload(c *gin.Context) {
+	var req GetDetailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	video, err := vh.service.GetDetail(c.Request.Context(), req.ID, viewerAccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key := video.PlayKey
+	if key == "" || media.IsExternal(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "video file is not managed by storage"})
+		return
+	}
+	downloadURL, err := vh.store.PresignGet(c.Request.Context(), key, vh.presignTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"url":       buildAbsoluteURL(c, urlPath),
-		"cover_url": buildAbsoluteURL(c, urlPath),
+		"url":        absoluteURL(c, downloadURL),
+		"expires_at": time.Now().Add(vh.presignTTL).Unix(),
 	})
 }
 
+// uploadKey 校验扩展名并生成 <kind>/<authorID>/<date>/<rand><ext> 形式的 key
+func uploadKey(kind string, authorId uint, filename string) (string, string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	var contentType string
+	switch kind {
+	case "videos":
+		if ext != ".mp4" {
+			return "", "", errors.New("only .mp4 is allowed")
+		}
+		contentType = "video/mp4"
+	case "covers":
+		switch ext {
+		case ".jpg", ".jpeg":
+			contentType = "image/jpeg"
+		case ".png":
+			contentType = "image/png"
+		case ".webp":
+			contentType = "image/webp"
+		default:
+			return "", "", errors.New("only .jpg/.jpeg/.png/.webp is allowed")
+		}
+	default:
+		return "", "", errors.New("unknown upload kind")
+	}
+	date := time.Now().Format("20060102")
+	return path.Join(kind, fmt.Sprintf("%d", authorId), date, randHex(16)+ext), contentType, nil
+}
+
 func randHex(n int) string {
 	b := make([]byte, n)
 	_, _ = rand.Read(b)
@@ -178,6 +427,23 @@ func buildAbsoluteURL(c *gin.Context, p string) string {
 	return fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, p)
 }
 
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// absoluteURL 本地存储返回的是相对地址，补全为当前请求的域名；对象存储地址原样返回
+func absoluteURL(c *gin.Context, u string) string {
+	if strings.HasPrefix(u, "/") {
+		return buildAbsoluteURL(c, u)
+	}
+	return u
+}
+
 func (vh *VideoHandler) DeleteVideo(c *gin.Context) {
 	var req DeleteVideoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -196,18 +462,32 @@ func (vh *VideoHandler) DeleteVideo(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "video deleted"})
 }
 
-func (vh *VideoHandler) ListByAuthorID(c *gin.Context) {
-	var req ListByAuthorIDRequest
+func (vh *VideoHandler) UpdateVideo(c *gin.Context) {
+	var req UpdateVideoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	videos, err := vh.service.ListByAuthorID(c.Request.Context(), req.AuthorID)
+	if req.ID == 0 {
+		c.JSON(400, gin.H{"error": "id is required"})
+		return
+	}
+	authorId, err := jwt.GetAccountID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	coverKey := req.CoverKey
+	if coverKey == nil {
+		coverKey = req.CoverURL
+	}
+	video, err := vh.service.Update(c.Request.Context(), req.ID, authorId, req.Title, req.Description, coverKey, req.Visibility)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(200, videos)
+	vh.media.FillURLs(video)
+	c.JSON(200, video)
 }
 
 func (vh *VideoHandler) GetDetail(c *gin.Context) {
@@ -216,11 +496,27 @@ func (vh *VideoHandler) GetDetail(c *gin.Context) {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	video, err := vh.service.GetDetail(c.Request.Context(), req.ID)
+	viewerAccountID, err := jwt.GetAccountID(c)
+	if err != nil {
+		viewerAccountID = 0
+	}
+	video, err := vh.service.GetDetail(c.Request.Context(), req.ID, viewerAccountID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	vh.media.FillURLs(video)
+	if viewerAccountID != 0 && vh.history != nil {
+		// 续播位置因人而异，不进详情缓存
+		if pos, err := vh.history.ResumePosition(c.Request.Context(), viewerAccountID, video.ID); err == nil {
+			video.ResumePosition = pos
+		}
+	}
+	if vh.series != nil {
+		if info, err := vh.series.EpisodeInfo(c.Request.Context(), video, viewerAccountID); err == nil {
+			video.Series = info
+		}
+	}
 	c.JSON(200, video)
 }
 
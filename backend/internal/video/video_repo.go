@@ -3,6 +3,9 @@ package video
 import (
 	"context"
 	"errors"
+	"feedsystem_video_go/internal/account"
+	"feedsystem_video_go/internal/social"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +18,29 @@ func NewVideoRepository(db *gorm.DB) *VideoRepository {
 	return &VideoRepository{db: db}
 }
 
+// Listed 过滤掉对观众不可见的视频（未发布、被隐藏、作者被封禁或暂停），所有面向观众的列表查询都应带上
+func Listed(db *gorm.DB) *gorm.DB {
+	return db.Where("videos.status = ? AND videos.hidden = ?", StatusPublished, false).
+		Where("videos.author_id NOT IN (?)", account.RestrictedIDs(db))
+}
+
+// Public 只保留公开视频，用于推荐/最新/热榜等公共流
+func Public(db *gorm.DB) *gorm.DB {
+	return db.Scopes(Listed).Where("videos.visibility = ?", VisibilityPublic)
+}
+
+// ViewableBy 与 CanView 一致的可见性过滤：作者本人全部可见，仅关注者可见的视频要求观众关注了作者，私密视频只对作者可见
+func ViewableBy(viewerAccountID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		followed := db.Session(&gorm.Session{NewDB: true}).
+			Model(&social.Social{}).
+			Select("vlogger_id").
+			Where("follower_id = ?", viewerAccountID)
+		return db.Where("videos.author_id = ? OR videos.visibility IN ? OR (videos.visibility = ? AND videos.author_id IN (?))",
+			viewerAccountID, []string{VisibilityPublic, VisibilityUnlisted, ""}, VisibilityFollowers, followed)
+	}
+}
+
 func (vr *VideoRepository) CreateVideo(ctx context.Context, video *Video) error {
 	if err := vr.db.WithContext(ctx).Create(video).Error; err != nil {
 		return err
@@ -22,6 +48,13 @@ func (vr *VideoRepository) CreateVideo(ctx context.Context, video *Video) error
 	return nil
 }
 
+func (vr *VideoRepository) UpdateVideo(ctx context.Context, id uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return vr.db.WithContext(ctx).Model(&Video{}).Where("id = ?", id).Updates(updates).Error
+}
+
 func (vr *VideoRepository) DeleteVideo(ctx context.Context, id uint) error {
 	if err := vr.db.WithContext(ctx).Delete(&Video{}, id).Error; err != nil {
 		return err
@@ -29,16 +62,76 @@ func (vr *VideoRepository) DeleteVideo(ctx context.Context, id uint) error {
 	return nil
 }
 
-func (vr *VideoRepository) ListByAuthorID(ctx context.Context, authorID int64) ([]Video, error) {
-	var videos []Video
-	if err := vr.db.WithContext(ctx).
-		Where("author_id = ?", authorID).
-		Order("create_time desc").
-		Offset(0).
-		Find(&videos).Error; err != nil {
-		return nil, err
+// seriesVideos 合集中作者的视频，visibilities 为空表示不过滤可见性（作者本人查看）
+func (vr *VideoRepository) seriesVideos(ctx context.Context, seriesID, authorID uint, visibilities []string) *gorm.DB {
+	query := vr.db.WithContext(ctx).Model(&Video{}).
+		Scopes(Listed).
+		Joins("JOIN series_episodes ON series_episodes.video_id = videos.id").
+		Where("series_episodes.series_id = ? AND videos.author_id = ?", seriesID, authorID)
+	if len(visibilities) > 0 {
+		query = query.Where("videos.visibility IN ?", visibilities)
 	}
-	return videos, nil
+	return query
+}
+
+// ListSeriesVideos 按剧集顺序取 afterPosition 之后的 limit 条，positions 为本页消耗的剧集位置，用于计算下一页游标
+func (vr *VideoRepository) ListSeriesVideos(ctx context.Context, seriesID, authorID uint, visibilities []string, afterPosition, limit int) ([]Video, []int, error) {
+	var refs []SeriesNeighbor
+	if err := vr.seriesVideos(ctx, seriesID, authorID, visibilities).
+		Select("videos.id AS video_id, series_episodes.position AS episode").
+		Where("series_episodes.position > ?", afterPosition).
+		Order("series_episodes.position asc").
+		Limit(limit).
+		Scan(&refs).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(refs) == 0 {
+		return []Video{}, []int{}, nil
+	}
+	ids := make([]uint, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.VideoID
+	}
+	var found []Video
+	if err := vr.db.WithContext(ctx).Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]Video, len(found))
+	for _, v := range found {
+		byID[v.ID] = v
+	}
+	videos := make([]Video, 0, len(refs))
+	positions := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		positions = append(positions, ref.Episode)
+		if v, ok := byID[ref.VideoID]; ok {
+			videos = append(videos, v)
+		}
+	}
+	return videos, positions, nil
+}
+
+// SeriesNeighbors position 前后最近的可见剧集，没有时为 nil
+func (vr *VideoRepository) SeriesNeighbors(ctx context.Context, seriesID, authorID uint, visibilities []string, position int) (prev, next *SeriesNeighbor, err error) {
+	find := func(cond, order string) (*SeriesNeighbor, error) {
+		var refs []SeriesNeighbor
+		if err := vr.seriesVideos(ctx, seriesID, authorID, visibilities).
+			Select("videos.id AS video_id, videos.title, series_episodes.position AS episode").
+			Where(cond, position).
+			Order(order).
+			Limit(1).
+			Scan(&refs).Error; err != nil || len(refs) == 0 {
+			return nil, err
+		}
+		return &refs[0], nil
+	}
+	if prev, err = find("series_episodes.position < ?", "series_episodes.position desc"); err != nil {
+		return nil, nil, err
+	}
+	if next, err = find("series_episodes.position > ?", "series_episodes.position asc"); err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
 }
 
 func (vr *VideoRepository) GetByID(ctx context.Context, id uint) (*Video, error) {
@@ -78,6 +171,32 @@ func (vr *VideoRepository) UpdatePopularity(ctx context.Context, id uint, change
 	return nil
 }
 
+// RefreshAuthorVideosCount 按已发布视频重新统计作者的作品数，发布/删除事件重复投递时结果不变
+func (vr *VideoRepository) RefreshAuthorVideosCount(ctx context.Context, authorID uint) error {
+	if authorID == 0 {
+		return nil
+	}
+	db := vr.db.WithContext(ctx)
+	count := db.Model(&Video{}).Select("COUNT(*)").Where("author_id = ? AND status = ?", authorID, StatusPublished)
+	return db.Model(&account.Account{}).Where("id = ?", authorID).UpdateColumn(account.CounterVideos, count).Error
+}
+
+// ChangeAuthorLikesReceived 调整视频作者的获赞计数
+func (vr *VideoRepository) ChangeAuthorLikesReceived(ctx context.Context, videoID uint, change int64) error {
+	return changeAuthorLikesReceived(vr.db.WithContext(ctx), videoID, change)
+}
+
+func changeAuthorLikesReceived(db *gorm.DB, videoID uint, change int64) error {
+	var v Video
+	if err := db.Unscoped().Select("id", "author_id").Where("id = ?", videoID).Take(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return account.ChangeCounter(db, v.AuthorID, account.CounterLikesReceived, change)
+}
+
 func (vr *VideoRepository) ChangeLikesCount(ctx context.Context, id uint, change int64) error {
 	if err := vr.db.WithContext(ctx).Model(&Video{}).
 		Where("id = ?", id).
@@ -95,3 +214,167 @@ func (vr *VideoRepository) ChangePopularity(ctx context.Context, id uint, change
 	}
 	return nil
 }
+
+// 累加播放统计，popularity 同步加上播放带来的热度
+func (vr *VideoRepository) AddPlayStats(ctx context.Context, id uint, stats PlayStats) error {
+	return vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"views_count":       gorm.Expr("views_count + ?", stats.Views),
+			"completions_count": gorm.Expr("completions_count + ?", stats.Completions),
+			"watch_time_ms":     gorm.Expr("watch_time_ms + ?", stats.WatchMs),
+			"popularity":        gorm.Expr("popularity + ?", stats.Popularity),
+		}).Error
+}
+
+// SetHidden 人工隐藏/恢复，隐藏时记录原因，恢复时清空
+func (vr *VideoRepository) SetHidden(ctx context.Context, id uint, hidden bool, reason string) error {
+	if !hidden {
+		reason = ""
+	}
+	result := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"hidden": hidden, "hidden_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		ok, err := vr.IsExist(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+// HideIfVisible 只隐藏当前可见的视频，已隐藏的保留原来的原因，返回是否由本次隐藏
+func (vr *VideoRepository) HideIfVisible(ctx context.Context, id uint, reason string) (bool, error) {
+	result := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ? AND hidden = ?", id, false).
+		Updates(map[string]interface{}{"hidden": true, "hidden_reason": reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UnhideIfReason 只恢复因 reason 被隐藏的视频，返回是否恢复
+func (vr *VideoRepository) UnhideIfReason(ctx context.Context, id uint, reason string) (bool, error) {
+	result := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ? AND hidden = ? AND hidden_reason = ?", id, true, reason).
+		Updates(map[string]interface{}{"hidden": false, "hidden_reason": ""})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (vr *VideoRepository) ListDrafts(ctx context.Context, authorID uint) ([]Video, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).
+		Where("author_id = ? AND status IN ?", authorID, []string{StatusDraft, StatusScheduled}).
+		Order("id desc").
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// MarkPublished 将草稿/定时视频置为已发布，条件更新保证多个 worker 并发时只有一个成功
+func (vr *VideoRepository) MarkPublished(ctx context.Context, id uint, publishTime time.Time) (bool, error) {
+	result := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ? AND status IN ?", id, []string{StatusDraft, StatusScheduled}).
+		Updates(map[string]interface{}{
+			"status":      StatusPublished,
+			"publish_at":  publishTime,
+			"create_time": publishTime,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (vr *VideoRepository) ListDueScheduled(ctx context.Context, now time.Time, limit int) ([]Video, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", StatusScheduled, now).
+		Order("publish_at asc").
+		Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// ListByMediaKey 引用该存储 key 作为播放文件或封面的视频
+func (vr *VideoRepository) ListByMediaKey(ctx context.Context, key string, limit int) ([]Video, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).
+		Where("play_key = ? OR cover_key = ?", key, key).
+		Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// ListWithURLKeys 仍保存完整地址（旧数据）的视频，按 id 游标分批
+func (vr *VideoRepository) ListWithURLKeys(ctx context.Context, afterID uint, limit int) ([]Video, error) {
+	var videos []Video
+	if err := vr.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Where("play_key LIKE ? OR play_key LIKE ? OR cover_key LIKE ? OR cover_key LIKE ?", "%://%", "/%", "%://%", "/%").
+		Order("id asc").
+		Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+func (vr *VideoRepository) CountPublishedSince(ctx context.Context, authorID uint, since time.Time) (int64, error) {
+	var count int64
+	if err := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("author_id = ? AND status = ? AND create_time >= ?", authorID, StatusPublished, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SetHLSByPlayKey 同步使用该视频文件的所有视频的 HLS 状态
+func (vr *VideoRepository) SetHLSByPlayKey(ctx context.Context, playKey, status, hlsKey string) error {
+	return vr.db.WithContext(ctx).Model(&Video{}).
+		Where("play_key = ?", playKey).
+		Updates(map[string]interface{}{"hls_status": status, "hls_key": hlsKey}).Error
+}
+
+func (vr *VideoRepository) ListIDsByPlayKey(ctx context.Context, playKey string) ([]uint, error) {
+	var ids []uint
+	if err := vr.db.WithContext(ctx).Model(&Video{}).
+		Where("play_key = ?", playKey).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListVisibleByIDs 批量读取观众可见的视频，可见性规则见 ViewableBy
+func (vr *VideoRepository) ListVisibleByIDs(ctx context.Context, ids []uint, viewerAccountID uint) ([]Video, error) {
+	var videos []Video
+	if len(ids) == 0 {
+		return videos, nil
+	}
+	if err := vr.db.WithContext(ctx).
+		Scopes(Listed).
+		Where("videos.id IN ?", ids).
+		Scopes(ViewableBy(viewerAccountID)).
+		Find(&videos).Error; err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+func (vr *VideoRepository) SetCollectCount(ctx context.Context, id uint, count int64) error {
+	return vr.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ?", id).
+		UpdateColumn("collect_count", count).Error
+}
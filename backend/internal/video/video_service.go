@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"feedsystem_video_go/internal/media"
 	"feedsystem_video_go/internal/middleware/rabbitmq"
 	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/social"
 )
 
 type VideoService struct {
@@ -18,10 +21,14 @@ type VideoService struct {
 	cache        *rediscache.Client
 	cacheTTL     time.Duration
 	popularityMQ *rabbitmq.PopularityMQ
+	videoMQ      *rabbitmq.VideoMQ
+	cleaner      *VideoCleaner
+	socialRepo   *social.SocialRepository
+	media        *MediaService
 }
 
-func NewVideoService(repo *VideoRepository, cache *rediscache.Client, popularityMQ *rabbitmq.PopularityMQ) *VideoService {
-	return &VideoService{repo: repo, cache: cache, cacheTTL: 5 * time.Minute, popularityMQ: popularityMQ}
+func NewVideoService(repo *VideoRepository, cache *rediscache.Client, popularityMQ *rabbitmq.PopularityMQ, videoMQ *rabbitmq.VideoMQ, cleaner *VideoCleaner, socialRepo *social.SocialRepository, media *MediaService) *VideoService {
+	return &VideoService{repo: repo, cache: cache, cacheTTL: 5 * time.Minute, popularityMQ: popularityMQ, videoMQ: videoMQ, cleaner: cleaner, socialRepo: socialRepo, media: media}
 }
 
 func (vs *VideoService) Publish(ctx context.Context, video *Video) error {
@@ -29,24 +36,383 @@ func (vs *VideoService) Publish(ctx context.Context, video *Video) error {
 		return errors.New("video is nil")
 	}
 	video.Title = strings.TrimSpace(video.Title)
-	video.PlayURL = strings.TrimSpace(video.PlayURL)
-	video.CoverURL = strings.TrimSpace(video.CoverURL)
+	video.PlayKey = vs.normalizeKey(video.PlayKey)
+	video.CoverKey = vs.normalizeKey(video.CoverKey)
 
 	if video.Title == "" {
 		return errors.New("title is required")
 	}
-	if video.PlayURL == "" {
+	if video.PlayKey == "" {
 		return errors.New("play url is required")
 	}
-	if video.CoverURL == "" {
+	if video.CoverKey == "" {
 		return errors.New("cover url is required")
 	}
+	if video.Visibility == "" {
+		video.Visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(video.Visibility) {
+		return errors.New("invalid visibility")
+	}
+	playObj, coverObj, err := vs.resolveMedia(ctx, video)
+	if err != nil {
+		return err
+	}
+	video.Status = StatusPublished
 	if err := vs.repo.CreateVideo(ctx, video); err != nil {
 		return err
 	}
+	vs.retainMedia(ctx, playObj, coverObj)
+	vs.requestPackaging(ctx, video.ID, video.AuthorID, playObj)
+	vs.afterPublish(ctx, video)
 	return nil
 }
 
+// SaveDraft 新建或更新草稿，草稿允许字段不完整，发布时再校验
+func (vs *VideoService) SaveDraft(ctx context.Context, draft *Video) (*Video, error) {
+	if draft == nil {
+		return nil, errors.New("draft is nil")
+	}
+	draft.Title = strings.TrimSpace(draft.Title)
+	draft.Description = strings.TrimSpace(draft.Description)
+	draft.PlayKey = vs.normalizeKey(draft.PlayKey)
+	draft.CoverKey = vs.normalizeKey(draft.CoverKey)
+	if draft.Visibility == "" {
+		draft.Visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(draft.Visibility) {
+		return nil, errors.New("invalid visibility")
+	}
+
+	playObj, coverObj, err := vs.resolveMedia(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.ID == 0 {
+		draft.Status = StatusDraft
+		if err := vs.repo.CreateVideo(ctx, draft); err != nil {
+			return nil, err
+		}
+		vs.retainMedia(ctx, playObj, coverObj)
+		vs.requestPackaging(ctx, draft.ID, draft.AuthorID, playObj)
+		return draft, nil
+	}
+
+	existing, err := vs.getDraft(ctx, draft.ID, draft.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := vs.repo.UpdateVideo(ctx, existing.ID, map[string]interface{}{
+		"title":              draft.Title,
+		"description":        draft.Description,
+		"play_key":           draft.PlayKey,
+		"cover_key":          draft.CoverKey,
+		"cover_variant_keys": variantKeysColumn(draft.CoverVariantKeys),
+		"visibility":         draft.Visibility,
+		"duration_ms":        draft.DurationMs,
+		"width":              draft.Width,
+		"height":             draft.Height,
+		"video_codec":        draft.VideoCodec,
+		"audio_codec":        draft.AudioCodec,
+		"hls_status":         draft.HLSStatus,
+		"hls_key":            draft.HLSKey,
+	}); err != nil {
+		return nil, err
+	}
+	// 草稿替换了文件：引用新文件，释放旧文件
+	if draft.PlayKey != existing.PlayKey {
+		vs.retainMedia(ctx, playObj)
+		vs.releaseMedia(ctx, existing.PlayKey)
+		vs.requestPackaging(ctx, existing.ID, existing.AuthorID, playObj)
+	}
+	if draft.CoverKey != existing.CoverKey {
+		vs.retainMedia(ctx, coverObj)
+		vs.releaseMedia(ctx, existing.CoverKey)
+	}
+	if vs.cache != nil {
+		_ = vs.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", existing.ID))
+	}
+	existing.Title = draft.Title
+	existing.Description = draft.Description
+	existing.PlayKey = draft.PlayKey
+	existing.CoverKey = draft.CoverKey
+	existing.CoverVariantKeys = draft.CoverVariantKeys
+	existing.Visibility = draft.Visibility
+	existing.DurationMs = draft.DurationMs
+	existing.Width = draft.Width
+	existing.Height = draft.Height
+	existing.VideoCodec = draft.VideoCodec
+	existing.AudioCodec = draft.AudioCodec
+	existing.HLSStatus = draft.HLSStatus
+	existing.HLSKey = draft.HLSKey
+	return existing, nil
+}
+
+func (vs *VideoService) ListDrafts(ctx context.Context, authorID uint) ([]Video, error) {
+	return vs.repo.ListDrafts(ctx, authorID)
+}
+
+// PublishDraft publishAt 为零值或已过去时立即发布，否则进入定时发布，由 worker 到点上线
+func (vs *VideoService) PublishDraft(ctx context.Context, id uint, authorID uint, publishAt time.Time) (*Video, error) {
+	draft, err := vs.getDraft(ctx, id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if draft.PlayKey == "" {
+		return nil, errors.New("play url is required")
+	}
+	if draft.CoverKey == "" {
+		return nil, errors.New("cover url is required")
+	}
+	// 保存草稿时已校验并引用了文件，这里只确认文件仍然有效
+	if _, _, err := vs.resolveMedia(ctx, draft); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !publishAt.IsZero() && publishAt.After(now) {
+		if err := vs.repo.UpdateVideo(ctx, id, map[string]interface{}{
+			"status":     StatusScheduled,
+			"publish_at": publishAt,
+		}); err != nil {
+			return nil, err
+		}
+		draft.Status = StatusScheduled
+		draft.PublishAt = &publishAt
+		return draft, nil
+	}
+
+	ok, err := vs.repo.MarkPublished(ctx, id, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("video already published")
+	}
+	draft.Status = StatusPublished
+	draft.PublishAt = &now
+	draft.CreateTime = now
+	vs.afterPublish(ctx, draft)
+	return draft, nil
+}
+
+// UnscheduleDraft 取消定时发布，退回草稿
+func (vs *VideoService) UnscheduleDraft(ctx context.Context, id uint, authorID uint) error {
+	draft, err := vs.getDraft(ctx, id, authorID)
+	if err != nil {
+		return err
+	}
+	if draft.Status != StatusScheduled {
+		return errors.New("video is not scheduled")
+	}
+	return vs.repo.UpdateVideo(ctx, id, map[string]interface{}{
+		"status":     StatusDraft,
+		"publish_at": nil,
+	})
+}
+
+func (vs *VideoService) DeleteDraft(ctx context.Context, id uint, authorID uint) error {
+	if _, err := vs.getDraft(ctx, id, authorID); err != nil {
+		return err
+	}
+	return vs.Delete(ctx, id, authorID)
+}
+
+func (vs *VideoService) getDraft(ctx context.Context, id uint, authorID uint) (*Video, error) {
+	video, err := vs.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if video.AuthorID != authorID {
+		return nil, errors.New("unauthorized")
+	}
+	if video.Status == StatusPublished {
+		return nil, errors.New("video already published")
+	}
+	return video, nil
+}
+
+// resolveMedia 校验 play_key/cover_key 指向已通过内容校验的上传文件，并把视频元数据写到 video 上；外部地址不做校验
+func (vs *VideoService) resolveMedia(ctx context.Context, video *Video) (*MediaObject, *MediaObject, error) {
+	if vs.media == nil {
+		return nil, nil, nil
+	}
+	playObj, err := vs.media.Lookup(ctx, video.PlayKey, MediaKindVideo, video.AuthorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if playObj != nil {
+		video.HLSStatus, video.HLSKey = playObj.HLSStatus, playObj.HLSKey
+		if video.HLSStatus == "" {
+			video.HLSStatus = HLSStatusPending
+		}
+		video.DurationMs = playObj.DurationMs
+		video.Width = playObj.Width
+		video.Height = playObj.Height
+		video.VideoCodec = playObj.VideoCodec
+		video.AudioCodec = playObj.AudioCodec
+	}
+	coverObj, err := vs.media.Lookup(ctx, video.CoverKey, MediaKindCover, video.AuthorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	video.CoverVariantKeys = nil
+	if coverObj != nil {
+		video.CoverVariantKeys = coverObj.Variants
+	}
+	return playObj, coverObj, nil
+}
+
+// requestPackaging 视频文件首次被引用时发出 video.uploaded 事件打包 HLS；MQ 不可用时在后台直接打包
+func (vs *VideoService) requestPackaging(ctx context.Context, videoID, authorID uint, playObj *MediaObject) {
+	if playObj == nil || playObj.HLSStatus != "" || vs.media == nil {
+		return
+	}
+	marked, err := vs.media.repo.MarkHLSPending(ctx, playObj.ID)
+	if err != nil || !marked {
+		return
+	}
+	if vs.videoMQ != nil {
+		if err := vs.videoMQ.Uploaded(ctx, videoID, authorID, playObj.Key); err == nil {
+			return
+		}
+	}
+	if vs.cleaner != nil {
+		go func(key string) {
+			if err := vs.cleaner.OnUploaded(context.Background(), key); err != nil {
+				log.Printf("video service: hls packaging failed: %v", err)
+			}
+		}(playObj.Key)
+	}
+}
+
+func (vs *VideoService) retainMedia(ctx context.Context, objs ...*MediaObject) {
+	if vs.media == nil {
+		return
+	}
+	for _, obj := range objs {
+		vs.media.Retain(ctx, obj)
+	}
+}
+
+func (vs *VideoService) releaseMedia(ctx context.Context, key string) {
+	if vs.media == nil {
+		return
+	}
+	if err := vs.media.Release(ctx, key); err != nil {
+		log.Printf("video service: failed to release %s: %v", key, err)
+	}
+}
+
+// variantKeysColumn map 形式的更新不经过字段序列化，手动编码为 JSON
+func variantKeysColumn(keys map[string]string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(keys)
+	return string(b)
+}
+
+func (vs *VideoService) normalizeKey(raw string) string {
+	if vs.media == nil {
+		return strings.TrimSpace(raw)
+	}
+	return vs.media.NormalizeKey(raw)
+}
+
+// 发布后的下游处理（缓存失效、关注流扩散），立即发布与定时发布共用
+func (vs *VideoService) afterPublish(ctx context.Context, video *Video) {
+	if vs.videoMQ != nil {
+		if err := vs.videoMQ.Published(ctx, video.ID, video.AuthorID); err == nil {
+			return
+		}
+	}
+	if vs.cleaner != nil {
+		if err := vs.cleaner.OnPublished(ctx, video.ID, video.AuthorID); err != nil {
+			log.Printf("video service: after publish failed: %v", err)
+		}
+	}
+}
+
+func (vs *VideoService) Update(ctx context.Context, id uint, authorID uint, title, description, coverKey, visibility *string) (*Video, error) {
+	video, err := vs.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if video.AuthorID != authorID {
+		return nil, errors.New("unauthorized")
+	}
+
+	updates := make(map[string]interface{})
+	if title != nil {
+		t := strings.TrimSpace(*title)
+		if t == "" {
+			return nil, errors.New("title is required")
+		}
+		updates["title"] = t
+		video.Title = t
+	}
+	if description != nil {
+		d := strings.TrimSpace(*description)
+		updates["description"] = d
+		video.Description = d
+	}
+	oldCoverKey := video.CoverKey
+	var coverObj *MediaObject
+	if coverKey != nil {
+		k := vs.normalizeKey(*coverKey)
+		if k == "" {
+			return nil, errors.New("cover url is required")
+		}
+		if k != video.CoverKey && vs.media != nil {
+			if coverObj, err = vs.media.Lookup(ctx, k, MediaKindCover, authorID); err != nil {
+				return nil, err
+			}
+			video.CoverVariantKeys = nil
+			if coverObj != nil {
+				video.CoverVariantKeys = coverObj.Variants
+			}
+			updates["cover_variant_keys"] = variantKeysColumn(video.CoverVariantKeys)
+		}
+		updates["cover_key"] = k
+		video.CoverKey = k
+	}
+	if visibility != nil {
+		if !IsValidVisibility(*visibility) {
+			return nil, errors.New("invalid visibility")
+		}
+		updates["visibility"] = *visibility
+		video.Visibility = *visibility
+	}
+	if len(updates) == 0 {
+		return video, nil
+	}
+	if err := vs.repo.UpdateVideo(ctx, id, updates); err != nil {
+		return nil, err
+	}
+	vs.retainMedia(ctx, coverObj)
+	if vs.cache != nil {
+		_ = vs.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+	}
+
+	if vs.videoMQ != nil {
+		if err := vs.videoMQ.Updated(ctx, id, authorID, video.CoverKey, oldCoverKey); err == nil {
+			return video, nil
+		}
+	}
+	// Fallback: 同步清理
+	if vs.cleaner != nil {
+		if err := vs.cleaner.OnUpdated(ctx, id, video.CoverKey, oldCoverKey); err != nil {
+			log.Printf("video service: cleanup after update failed: %v", err)
+		}
+	}
+	return video, nil
+}
+
 func (vs *VideoService) Delete(ctx context.Context, id uint, authorID uint) error {
 	video, err := vs.repo.GetByID(ctx, id)
 	if err != nil {
@@ -58,6 +424,7 @@ func (vs *VideoService) Delete(ctx context.Context, id uint, authorID uint) erro
 	if video.AuthorID != authorID {
 		return errors.New("unauthorized")
 	}
+	// 软删除，点赞/评论/文件等由 video.deleted 事件异步清理
 	if err := vs.repo.DeleteVideo(ctx, id); err != nil {
 		return err
 	}
@@ -65,18 +432,116 @@ func (vs *VideoService) Delete(ctx context.Context, id uint, authorID uint) erro
 		cacheKey := fmt.Sprintf("video:detail:id=%d", id)
 		_ = vs.cache.Del(context.Background(), cacheKey)
 	}
+
+	if vs.videoMQ != nil {
+		if err := vs.videoMQ.Deleted(ctx, id, authorID, video.PlayKey, video.CoverKey); err == nil {
+			return nil
+		}
+	}
+	// Fallback: 同步清理
+	if vs.cleaner != nil {
+		if err := vs.cleaner.OnDeleted(ctx, id, video.AuthorID, video.PlayKey, video.CoverKey); err != nil {
+			log.Printf("video service: cleanup after delete failed: %v", err)
+		}
+	}
 	return nil
 }
 
-func (vs *VideoService) ListByAuthorID(ctx context.Context, authorID uint) ([]Video, error) {
-	videos, err := vs.repo.ListByAuthorID(ctx, int64(authorID))
+// AuthorVisibilities 观众在作者主页能看到的可见性，返回 nil 表示不过滤（作者本人）
+func (vs *VideoService) AuthorVisibilities(ctx context.Context, authorID uint, viewerAccountID uint) ([]string, error) {
+	if viewerAccountID == authorID {
+		return nil, nil
+	}
+	visibilities := []string{VisibilityPublic}
+	followed, err := vs.isFollower(ctx, viewerAccountID, authorID)
 	if err != nil {
 		return nil, err
 	}
-	return videos, nil
+	if followed {
+		visibilities = append(visibilities, VisibilityFollowers)
+	}
+	return visibilities, nil
 }
 
-func (vs *VideoService) GetDetail(ctx context.Context, id uint) (*Video, error) {
+func (vs *VideoService) GetDetail(ctx context.Context, id uint, viewerAccountID uint) (*Video, error) {
+	video, err := vs.getDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if video.Hidden {
+		return nil, errors.New("video not found")
+	}
+	ok, err := vs.CanView(ctx, video, viewerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// 不暴露私密视频是否存在
+		return nil, errors.New("video not found")
+	}
+	return video, nil
+}
+
+// CanView 按可见性判断观众能否访问视频，viewerAccountID 为 0 表示未登录
+func (vs *VideoService) CanView(ctx context.Context, video *Video, viewerAccountID uint) (bool, error) {
+	if viewerAccountID != 0 && viewerAccountID == video.AuthorID {
+		return true, nil
+	}
+	if video.Status != StatusPublished && video.Status != "" {
+		return false, nil
+	}
+	switch video.Visibility {
+	case VisibilityPublic, VisibilityUnlisted, "":
+		return true, nil
+	case VisibilityFollowers:
+		return vs.isFollower(ctx, viewerAccountID, video.AuthorID)
+	default:
+		return false, nil
+	}
+}
+
+// MediaAccess 判断观众能否读取上传文件：被任一可见视频引用即可访问，未被引用的文件只有上传者可访问
+func (vs *VideoService) MediaAccess(ctx context.Context, key string, viewerAccountID uint) (bool, error) {
+	// 缩略图、HLS 分片与原文件权限一致
+	if src, ok := media.VariantSource(key); ok {
+		key = src
+	} else if src, ok := media.HLSSource(key); ok {
+		key = src
+	}
+	videos, err := vs.repo.ListByMediaKey(ctx, key, 20)
+	if err != nil {
+		return false, err
+	}
+	if len(videos) == 0 {
+		if vs.media == nil {
+			return false, nil
+		}
+		return vs.media.IsUploader(ctx, key, viewerAccountID)
+	}
+	for i := range videos {
+		v := &videos[i]
+		if v.Hidden && v.AuthorID != viewerAccountID {
+			continue
+		}
+		ok, err := vs.CanView(ctx, v, viewerAccountID)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (vs *VideoService) isFollower(ctx context.Context, followerID, vloggerID uint) (bool, error) {
+	if followerID == 0 || vs.socialRepo == nil {
+		return false, nil
+	}
+	return vs.socialRepo.IsFollowed(ctx, &social.Social{FollowerID: followerID, VloggerID: vloggerID})
+}
+
+func (vs *VideoService) getDetail(ctx context.Context, id uint) (*Video, error) {
 	cacheKey := fmt.Sprintf("video:detail:id=%d", id)
 
 	getCached := func() (*Video, bool) {
@@ -198,3 +663,47 @@ func (vs *VideoService) UpdatePopularity(ctx context.Context, id uint, change in
 	}
 	return nil
 }
+
+// 审核隐藏/恢复视频，隐藏时同时移出热榜；人工操作会覆盖举报自动隐藏的状态
+func (vs *VideoService) SetHidden(ctx context.Context, id uint, hidden bool) error {
+	if err := vs.repo.SetHidden(ctx, id, hidden, HiddenByAdmin); err != nil {
+		return err
+	}
+	vs.onHiddenChanged(ctx, id, hidden)
+	return nil
+}
+
+// AutoHide 举报达到阈值时隐藏，已被人工下架的视频不受影响
+func (vs *VideoService) AutoHide(ctx context.Context, id uint) error {
+	changed, err := vs.repo.HideIfVisible(ctx, id, HiddenByReport)
+	if err != nil || !changed {
+		return err
+	}
+	vs.onHiddenChanged(ctx, id, true)
+	return nil
+}
+
+// RestoreAutoHidden 误报结案时恢复，只恢复由举报自动隐藏的视频
+func (vs *VideoService) RestoreAutoHidden(ctx context.Context, id uint) error {
+	changed, err := vs.repo.UnhideIfReason(ctx, id, HiddenByReport)
+	if err != nil || !changed {
+		return err
+	}
+	vs.onHiddenChanged(ctx, id, false)
+	return nil
+}
+
+// onHiddenChanged 隐藏/恢复后失效详情、热榜和各类流缓存，与删除/更新视频相同
+func (vs *VideoService) onHiddenChanged(ctx context.Context, id uint, hidden bool) {
+	if vs.cache == nil {
+		return
+	}
+	if hidden {
+		RemoveFromPopularityCache(ctx, vs.cache, id)
+	} else {
+		_ = vs.cache.Del(context.Background(), fmt.Sprintf("video:detail:id=%d", id))
+	}
+	if vs.cleaner != nil {
+		vs.cleaner.invalidateFeeds(ctx)
+	}
+}
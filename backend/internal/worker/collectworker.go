@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/video"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type CollectWorker struct {
+	ch          *amqp.Channel
+	collections *video.CollectionRepository
+	videos      *video.VideoRepository
+	queue       string
+}
+
+func NewCollectWorker(ch *amqp.Channel, collections *video.CollectionRepository, videos *video.VideoRepository, queue string) *CollectWorker {
+	return &CollectWorker{ch: ch, collections: collections, videos: videos, queue: queue}
+}
+
+func (w *CollectWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.collections == nil || w.videos == nil {
+		return errors.New("collect worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+			w.handleDelivery(ctx, d)
+		}
+	}
+}
+
+func (w *CollectWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("collect worker: failed to process message: %v", err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *CollectWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.CollectEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil
+	}
+	if evt.VideoID == 0 {
+		return nil
+	}
+
+	switch evt.Action {
+	case "collect", "uncollect":
+		// 收藏条目已同步写入，这里只按条目重算计数，消息重复或乱序都不影响结果
+		return video.RefreshCollectCount(ctx, w.collections, w.videos, evt.VideoID)
+	default:
+		return nil
+	}
+}
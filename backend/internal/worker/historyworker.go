@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/video"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type HistoryWorker struct {
+	ch    *amqp.Channel
+	repo  *video.HistoryRepository
+	queue string
+}
+
+func NewHistoryWorker(ch *amqp.Channel, repo *video.HistoryRepository, queue string) *HistoryWorker {
+	return &HistoryWorker{ch: ch, repo: repo, queue: queue}
+}
+
+func (w *HistoryWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.repo == nil {
+		return errors.New("history worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+			w.handleDelivery(ctx, d)
+		}
+	}
+}
+
+func (w *HistoryWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("history worker: failed to process message: %v", err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *HistoryWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.HistoryEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil
+	}
+	if evt.AccountID == 0 || evt.VideoID == 0 || evt.WatchedAt.IsZero() {
+		return nil
+	}
+
+	switch evt.Action {
+	case "watch":
+		return w.repo.Upsert(ctx, &video.WatchHistory{
+			AccountID:  evt.AccountID,
+			VideoID:    evt.VideoID,
+			PositionMs: evt.PositionMs,
+			WatchedAt:  evt.WatchedAt,
+		})
+	default:
+		return nil
+	}
+}
@@ -109,6 +109,9 @@ func (w *LikeWorker) applyLike(ctx context.Context, userID, videoID uint) error
 	if err := w.videos.ChangeLikesCount(ctx, videoID, 1); err != nil {
 		return err
 	}
+	if err := w.videos.ChangeAuthorLikesReceived(ctx, videoID, 1); err != nil {
+		return err
+	}
 	return w.videos.ChangePopularity(ctx, videoID, 1)
 }
 
@@ -132,5 +135,8 @@ func (w *LikeWorker) applyUnlike(ctx context.Context, userID, videoID uint) erro
 	if err := w.videos.ChangeLikesCount(ctx, videoID, -1); err != nil {
 		return err
 	}
+	if err := w.videos.ChangeAuthorLikesReceived(ctx, videoID, -1); err != nil {
+		return err
+	}
 	return w.videos.ChangePopularity(ctx, videoID, -1)
 }
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/video"
+	"log"
+	"time"
+)
+
+// OrphanMediaSweeper 定期删除上传后一直未被视频引用的文件
+type OrphanMediaSweeper struct {
+	sweeper  *video.MediaSweeper
+	interval time.Duration
+}
+
+func NewOrphanMediaSweeper(sweeper *video.MediaSweeper, interval time.Duration) *OrphanMediaSweeper {
+	return &OrphanMediaSweeper{sweeper: sweeper, interval: interval}
+}
+
+func (s *OrphanMediaSweeper) Run(ctx context.Context) error {
+	if s == nil || s.sweeper == nil {
+		return errors.New("orphan media sweeper is not initialized")
+	}
+	if s.interval <= 0 {
+		return errors.New("interval is required")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		report, err := s.sweeper.Sweep(ctx, false)
+		if err != nil {
+			log.Printf("orphan media sweeper: sweep failed: %v", err)
+		}
+		if report != nil && report.Deleted > 0 {
+			log.Printf("orphan media sweeper: removed %d files, freed %d bytes", report.Deleted, report.FreedBytes)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/video"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// PackagingWorker 消费 video.uploaded 事件，把上传的 MP4 打包为 HLS
+type PackagingWorker struct {
+	ch      *amqp.Channel
+	cleaner *video.VideoCleaner
+	queue   string
+}
+
+func NewPackagingWorker(ch *amqp.Channel, cleaner *video.VideoCleaner, queue string) *PackagingWorker {
+	return &PackagingWorker{ch: ch, cleaner: cleaner, queue: queue}
+}
+
+func (w *PackagingWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.cleaner == nil {
+		return errors.New("packaging worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+			w.handleDelivery(ctx, d)
+		}
+	}
+}
+
+func (w *PackagingWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("packaging worker: failed to process message: %v", err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *PackagingWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.VideoEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		// 解析事件失败，直接丢弃
+		return nil
+	}
+	if evt.Action != "uploaded" || evt.PlayKey == "" {
+		return nil
+	}
+	return w.cleaner.OnUploaded(ctx, evt.PlayKey)
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	rediscache "feedsystem_video_go/internal/middleware/redis"
+	"feedsystem_video_go/internal/video"
+	"log"
+	"time"
+)
+
+// PlayStatsFlusher 定期把 Redis 中累加的播放统计批量写回 MySQL
+type PlayStatsFlusher struct {
+	cache    *rediscache.Client
+	videos   *video.VideoRepository
+	interval time.Duration
+}
+
+func NewPlayStatsFlusher(cache *rediscache.Client, videos *video.VideoRepository, interval time.Duration) *PlayStatsFlusher {
+	return &PlayStatsFlusher{cache: cache, videos: videos, interval: interval}
+}
+
+func (f *PlayStatsFlusher) Run(ctx context.Context) error {
+	if f == nil || f.cache == nil || f.videos == nil {
+		return errors.New("play stats flusher is not initialized")
+	}
+	if f.interval <= 0 {
+		return errors.New("interval is required")
+	}
+
+	if n, err := video.RecoverPlayStats(ctx, f.cache); err != nil {
+		log.Printf("play stats flusher: recover drains failed: %v", err)
+	} else if n > 0 {
+		log.Printf("play stats flusher: recovered %d orphaned drains", n)
+	}
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// 退出前把已累加的统计写回
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, _ = video.FlushPlayStats(flushCtx, f.cache, f.videos)
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if _, err := video.FlushPlayStats(ctx, f.cache, f.videos); err != nil {
+			log.Printf("play stats flusher: flush failed: %v", err)
+		}
+	}
+}
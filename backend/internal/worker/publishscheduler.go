@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/video"
+	"log"
+	"time"
+)
+
+// PublishScheduler 定时扫描到点的定时视频并发布，发布后的下游处理与立即发布一致
+type PublishScheduler struct {
+	videos   *video.VideoRepository
+	cleaner  *video.VideoCleaner
+	interval time.Duration
+	batch    int
+}
+
+func NewPublishScheduler(videos *video.VideoRepository, cleaner *video.VideoCleaner, interval time.Duration) *PublishScheduler {
+	return &PublishScheduler{videos: videos, cleaner: cleaner, interval: interval, batch: 100}
+}
+
+func (s *PublishScheduler) Run(ctx context.Context) error {
+	if s == nil || s.videos == nil || s.cleaner == nil {
+		return errors.New("publish scheduler is not initialized")
+	}
+	if s.interval <= 0 {
+		return errors.New("interval is required")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PublishScheduler) tick(ctx context.Context) {
+	due, err := s.videos.ListDueScheduled(ctx, time.Now(), s.batch)
+	if err != nil {
+		log.Printf("publish scheduler: failed to list due videos: %v", err)
+		return
+	}
+	for _, v := range due {
+		publishTime := time.Now()
+		if v.PublishAt != nil {
+			publishTime = *v.PublishAt
+		}
+		ok, err := s.videos.MarkPublished(ctx, v.ID, publishTime)
+		if err != nil {
+			log.Printf("publish scheduler: failed to publish video %d: %v", v.ID, err)
+			continue
+		}
+		if !ok {
+			// 已被其他实例发布或被作者取消
+			continue
+		}
+		if err := s.cleaner.OnPublished(ctx, v.ID, v.AuthorID); err != nil {
+			log.Printf("publish scheduler: after publish failed for video %d: %v", v.ID, err)
+		}
+		log.Printf("publish scheduler: video %d published", v.ID)
+	}
+}
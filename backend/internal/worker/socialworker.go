@@ -8,7 +8,6 @@ import (
 	"feedsystem_video_go/internal/social"
 	"log"
 
-	"github.com/go-sql-driver/mysql"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -76,25 +75,15 @@ func (w *SocialWorker) process(ctx context.Context, body []byte) error {
 		return nil
 	}
 
+	// 关系和计数在同一事务中写入，重复投递的事件不会重复计数
+	rel := &social.Social{FollowerID: evt.FollowerID, VloggerID: evt.VloggerID}
 	switch evt.Action {
 	case "follow":
-		err := w.repo.Follow(ctx, &social.Social{
-			FollowerID: evt.FollowerID,
-			VloggerID:  evt.VloggerID,
-		})
-		if err == nil {
-			return nil
-		}
-		var mysqlErr *mysql.MySQLError
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-			return nil
-		}
+		_, err := w.repo.Follow(ctx, rel)
 		return err
 	case "unfollow":
-		return w.repo.Unfollow(ctx, &social.Social{
-			FollowerID: evt.FollowerID,
-			VloggerID:  evt.VloggerID,
-		})
+		_, err := w.repo.Unfollow(ctx, rel)
+		return err
 	default:
 		return nil
 	}
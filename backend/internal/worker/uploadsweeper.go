@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"feedsystem_video_go/internal/video"
+	"log"
+	"time"
+)
+
+// UploadSessionSweeper 定期清理过期的分片上传会话和残留分片
+type UploadSessionSweeper struct {
+	uploads  *video.UploadService
+	interval time.Duration
+}
+
+func NewUploadSessionSweeper(uploads *video.UploadService, interval time.Duration) *UploadSessionSweeper {
+	return &UploadSessionSweeper{uploads: uploads, interval: interval}
+}
+
+func (s *UploadSessionSweeper) Run(ctx context.Context) error {
+	if s == nil || s.uploads == nil {
+		return errors.New("upload session sweeper is not initialized")
+	}
+	if s.interval <= 0 {
+		return errors.New("interval is required")
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		n, err := s.uploads.CleanupExpired(ctx)
+		if err != nil {
+			log.Printf("upload session sweeper: cleanup failed: %v", err)
+		} else if n > 0 {
+			log.Printf("upload session sweeper: removed %d expired sessions", n)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"feedsystem_video_go/internal/middleware/rabbitmq"
+	"feedsystem_video_go/internal/video"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type VideoWorker struct {
+	ch      *amqp.Channel
+	cleaner *video.VideoCleaner
+	queue   string
+}
+
+func NewVideoWorker(ch *amqp.Channel, cleaner *video.VideoCleaner, queue string) *VideoWorker {
+	return &VideoWorker{ch: ch, cleaner: cleaner, queue: queue}
+}
+
+func (w *VideoWorker) Run(ctx context.Context) error {
+	if w == nil || w.ch == nil || w.cleaner == nil {
+		return errors.New("video worker is not initialized")
+	}
+	if w.queue == "" {
+		return errors.New("queue is required")
+	}
+
+	deliveries, err := w.ch.Consume(
+		w.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("deliveries channel closed")
+			}
+			w.handleDelivery(ctx, d)
+		}
+	}
+}
+
+func (w *VideoWorker) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	if err := w.process(ctx, d.Body); err != nil {
+		log.Printf("video worker: failed to process message: %v", err)
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+func (w *VideoWorker) process(ctx context.Context, body []byte) error {
+	var evt rabbitmq.VideoEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		// 解析事件失败，直接丢弃
+		return nil
+	}
+	if evt.VideoID == 0 {
+		return nil
+	}
+
+	switch evt.Action {
+	case "published":
+		return w.cleaner.OnPublished(ctx, evt.VideoID, evt.AuthorID)
+	case "deleted":
+		return w.cleaner.OnDeleted(ctx, evt.VideoID, evt.AuthorID, evt.PlayKey, evt.CoverKey)
+	case "updated":
+		return w.cleaner.OnUpdated(ctx, evt.VideoID, evt.CoverKey, evt.OldCoverKey)
+	default:
+		return nil
+	}
+}
@@ -1,5 +1,5 @@
 import { postJson } from './client'
-import type { Account, MessageResponse, TokenResponse } from './types'
+import type { Account, MessageResponse, Profile, TokenResponse, UpdateProfileRequest } from './types'
 
 export function register(username: string, password: string) {
   return postJson<MessageResponse>('/account/register', { username, password })
@@ -17,6 +17,10 @@ export function rename(newUsername: string) {
   return postJson<TokenResponse>('/account/rename', { new_username: newUsername }, { authRequired: true })
 }
 
+export function setLikesPublic(likesPublic: boolean) {
+  return postJson<{ likes_public: boolean }>('/account/setLikesPublic', { likes_public: likesPublic }, { authRequired: true })
+}
+
 export function changePassword(username: string, oldPassword: string, newPassword: string) {
   return postJson<MessageResponse>('/account/changePassword', {
     username,
@@ -32,3 +36,12 @@ export function findById(id: number) {
 export function findByUsername(username: string) {
   return postJson<Account>('/account/findByUsername', { username })
 }
+
+export function getProfile(id: number) {
+  return postJson<Profile>('/account/getProfile', { id })
+}
+
+// 头像先通过 video.uploadCover 上传，再把返回的 cover_key 作为 avatar_key 提交
+export function updateProfile(req: UpdateProfileRequest) {
+  return postJson<Profile>('/account/profile', req, { authRequired: true })
+}
@@ -0,0 +1,63 @@
+import { postJson } from './client'
+import type { MessageResponse, Video } from './types'
+
+export type Collection = {
+  id: number
+  account_id: number
+  name: string
+  visibility: 'public' | 'private'
+  videos_count: number
+  created_at: string
+  updated_at: string
+}
+
+export type ListCollectionsResponse = {
+  collections: Collection[]
+  next_before_id: number
+  has_more: boolean
+}
+
+export type CollectionVideo = { item_id: number; collected_at: number; video: Video }
+
+export type ListCollectionVideosResponse = {
+  collection: Collection
+  videos: CollectionVideo[]
+  next_before_item_id: number
+  has_more: boolean
+}
+
+export function createCollection(name: string, visibility: 'public' | 'private' = 'private') {
+  return postJson<Collection>('/collection/create', { name, visibility }, { authRequired: true })
+}
+
+export function renameCollection(id: number, name: string) {
+  return postJson<Collection>('/collection/rename', { id, name }, { authRequired: true })
+}
+
+export function setCollectionVisibility(id: number, visibility: 'public' | 'private') {
+  return postJson<Collection>('/collection/setVisibility', { id, visibility }, { authRequired: true })
+}
+
+export function deleteCollection(id: number) {
+  return postJson<MessageResponse>('/collection/delete', { id }, { authRequired: true })
+}
+
+export function addVideo(collectionId: number, videoId: number) {
+  return postJson<MessageResponse>('/collection/addVideo', { collection_id: collectionId, video_id: videoId }, { authRequired: true })
+}
+
+export function removeVideo(collectionId: number, videoId: number) {
+  return postJson<MessageResponse>('/collection/removeVideo', { collection_id: collectionId, video_id: videoId }, { authRequired: true })
+}
+
+export function listCollections(accountId?: number, limit = 20, beforeId?: number) {
+  return postJson<ListCollectionsResponse>('/collection/list', { account_id: accountId, limit, before_id: beforeId })
+}
+
+export function listCollectionVideos(collectionId: number, limit = 20, beforeItemId?: number) {
+  return postJson<ListCollectionVideosResponse>('/collection/listVideos', {
+    collection_id: collectionId,
+    limit,
+    before_item_id: beforeItemId,
+  })
+}
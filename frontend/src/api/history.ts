@@ -0,0 +1,27 @@
+import { postJson } from './client'
+import type { MessageResponse, Video } from './types'
+
+export type HistoryItem = { video: Video; position_ms: number; watched_at: number }
+
+export type ListHistoryResponse = {
+  items: HistoryItem[]
+  next_before_time: number
+  next_before_video_id: number
+  has_more: boolean
+}
+
+export function listHistory(limit: number, beforeTime?: number, beforeVideoId?: number) {
+  return postJson<ListHistoryResponse>(
+    '/history/list',
+    { limit, before_time: beforeTime, before_video_id: beforeVideoId },
+    { authRequired: true },
+  )
+}
+
+export function deleteHistory(videoId: number) {
+  return postJson<MessageResponse>('/history/delete', { video_id: videoId }, { authRequired: true })
+}
+
+export function clearHistory() {
+  return postJson<MessageResponse>('/history/clear', {}, { authRequired: true })
+}
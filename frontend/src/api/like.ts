@@ -13,6 +13,30 @@ export function isLiked(videoId: number) {
   return postJson<IsLikedResponse>('/like/isLiked', { video_id: videoId }, { authRequired: true })
 }
 
-export function listMyLikedVideos() {
-  return postJson<Video[]>('/like/listMyLikedVideos', {}, { authRequired: true })
+export type LikedVideo = { like_id: number; liked_at: number; video: Video }
+
+export type ListLikedVideosResponse = {
+  videos: LikedVideo[]
+  next_before_time: number
+  next_before_like_id: number
+  has_more: boolean
+}
+
+export type LikedVideosCursor = { beforeTime?: number; beforeLikeId?: number }
+
+export function listMyLikedVideos(limit?: number, cursor: LikedVideosCursor = {}) {
+  return postJson<ListLikedVideosResponse>(
+    '/like/listMyLikedVideos',
+    { limit, before_time: cursor.beforeTime, before_like_id: cursor.beforeLikeId },
+    { authRequired: true },
+  )
+}
+
+export function listLikedVideos(accountId: number, limit?: number, cursor: LikedVideosCursor = {}) {
+  return postJson<ListLikedVideosResponse>('/like/listLikedVideos', {
+    account_id: accountId,
+    limit,
+    before_time: cursor.beforeTime,
+    before_like_id: cursor.beforeLikeId,
+  })
 }
@@ -0,0 +1,46 @@
+import { postJson } from './client'
+import type { MessageResponse, Video } from './types'
+
+export type Series = {
+  id: number
+  author_id: number
+  title: string
+  description?: string
+  episodes_count: number
+  created_at: string
+  updated_at: string
+}
+
+export type SeriesDetail = { series: Series; videos: Video[]; next_after_position: number; has_more: boolean }
+
+export function createSeries(title: string, description = '') {
+  return postJson<Series>('/series/create', { title, description }, { authRequired: true })
+}
+
+export function updateSeries(id: number, input: { title?: string; description?: string }) {
+  return postJson<Series>('/series/update', { id, ...input }, { authRequired: true })
+}
+
+export function deleteSeries(id: number) {
+  return postJson<MessageResponse>('/series/delete', { id }, { authRequired: true })
+}
+
+export function addVideo(seriesId: number, videoId: number, position?: number) {
+  return postJson<MessageResponse>('/series/addVideo', { series_id: seriesId, video_id: videoId, position }, { authRequired: true })
+}
+
+export function removeVideo(seriesId: number, videoId: number) {
+  return postJson<MessageResponse>('/series/removeVideo', { series_id: seriesId, video_id: videoId }, { authRequired: true })
+}
+
+export function reorder(seriesId: number, videoIds: number[]) {
+  return postJson<MessageResponse>('/series/reorder', { series_id: seriesId, video_ids: videoIds }, { authRequired: true })
+}
+
+export function getSeries(id: number, afterPosition = 0, limit = 20) {
+  return postJson<SeriesDetail>('/series/get', { id, after_position: afterPosition, limit })
+}
+
+export function listByAuthorId(authorId: number) {
+  return postJson<Series[]>('/series/listByAuthorID', { author_id: authorId })
+}
@@ -1,5 +1,11 @@
 import { postJson } from './client'
-import type { GetAllFollowersResponse, GetAllVloggersResponse, MessageResponse } from './types'
+import type {
+  ListFollowersResponse,
+  ListFollowingResponse,
+  ListFriendsResponse,
+  MessageResponse,
+  RelationResponse,
+} from './types'
 
 export function follow(vloggerId: number) {
   return postJson<MessageResponse>('/social/follow', { vlogger_id: vloggerId }, { authRequired: true })
@@ -9,18 +15,30 @@ export function unfollow(vloggerId: number) {
   return postJson<MessageResponse>('/social/unfollow', { vlogger_id: vloggerId }, { authRequired: true })
 }
 
-export function getAllFollowers(vloggerId?: number) {
-  return postJson<GetAllFollowersResponse>(
-    '/social/getAllFollowers',
-    vloggerId ? { vlogger_id: vloggerId } : {},
+export function listFollowers(vloggerId?: number, limit?: number, beforeId?: number) {
+  return postJson<ListFollowersResponse>(
+    '/social/listFollowers',
+    { vlogger_id: vloggerId, limit, before_id: beforeId },
     { authRequired: true },
   )
 }
 
-export function getAllVloggers(followerId?: number) {
-  return postJson<GetAllVloggersResponse>(
-    '/social/getAllVloggers',
-    followerId ? { follower_id: followerId } : {},
+export function listFollowing(followerId?: number, limit?: number, beforeId?: number) {
+  return postJson<ListFollowingResponse>(
+    '/social/listFollowing',
+    { follower_id: followerId, limit, before_id: beforeId },
     { authRequired: true },
   )
 }
+
+export function listFriends(accountId?: number, limit?: number, beforeId?: number) {
+  return postJson<ListFriendsResponse>(
+    '/social/listFriends',
+    { account_id: accountId, limit, before_id: beforeId },
+    { authRequired: true },
+  )
+}
+
+export function relation(accountIds: number[]) {
+  return postJson<RelationResponse>('/social/relation', { account_ids: accountIds }, { authRequired: true })
+}
@@ -5,6 +5,31 @@ export type TokenResponse = { token: string }
 export type Account = {
   id: number
   username: string
+  likes_public?: boolean
+}
+
+export type Profile = {
+  id: number
+  username: string
+  display_name: string
+  avatar_url: string
+  bio: string
+  // 性别和生日只在查看自己的资料时返回
+  gender?: '' | 'male' | 'female' | 'other'
+  birthday?: string
+  followers_count: number
+  following_count: number
+  likes_received: number
+  videos_count: number
+  likes_public: boolean
+}
+
+export type UpdateProfileRequest = {
+  display_name?: string
+  avatar_key?: string
+  bio?: string
+  gender?: NonNullable<Profile['gender']>
+  birthday?: string
 }
 
 export type Video = {
@@ -14,9 +39,25 @@ export type Video = {
   title: string
   description?: string
   play_url: string
+  mp4_url?: string
   cover_url: string
   create_time: string
   likes_count: number
+  collect_count?: number
+  views_count?: number
+  resume_position?: number
+  series?: SeriesInfo
+}
+
+export type SeriesNeighbor = { video_id: number; title: string; episode: number }
+
+export type SeriesInfo = {
+  id: number
+  title: string
+  episode: number
+  episodes_count: number
+  prev?: SeriesNeighbor
+  next?: SeriesNeighbor
 }
 
 export type Comment = {
@@ -28,9 +69,14 @@ export type Comment = {
   created_at: string
 }
 
+// blocked: 对方账号已被封禁
+export type Relation = 'none' | 'following' | 'followed-by' | 'mutual' | 'blocked'
+
 export type FeedAuthor = {
   id: number
   username: string
+  // 未登录或作者是自己时不返回
+  relation?: Relation
 }
 
 export type FeedVideoItem = {
@@ -39,12 +85,22 @@ export type FeedVideoItem = {
   title: string
   description?: string
   play_url: string
+  mp4_url?: string
   cover_url: string
   create_time: number
   likes_count: number
+  collect_count?: number
+  views_count?: number
+  resume_position?: number
   is_liked: boolean
 }
 
+export type ListByAuthorResponse = {
+  video_list: FeedVideoItem[]
+  next_cursor?: string
+  has_more: boolean
+}
+
 export type ListLatestResponse = {
   video_list: FeedVideoItem[]
   next_time: number
@@ -78,10 +134,38 @@ export type IsLikedResponse = {
   is_liked: boolean
 }
 
-export type GetAllFollowersResponse = {
-  followers: Account[]
+export type PublicAccount = {
+  id: number
+  username: string
+  display_name: string
+  avatar_url: string
+}
+
+export type RelationAccount = PublicAccount & {
+  is_following: boolean
+  is_followed_by: boolean
+}
+
+export type ListFollowersResponse = {
+  followers: RelationAccount[]
+  total: number
+  next_before_id: number
+  has_more: boolean
+}
+
+export type ListFriendsResponse = {
+  friends: RelationAccount[]
+  next_before_id: number
+  has_more: boolean
 }
 
-export type GetAllVloggersResponse = {
-  vloggers: Account[]
+export type RelationResponse = {
+  relations: Record<string, Relation>
+}
+
+export type ListFollowingResponse = {
+  following: RelationAccount[]
+  total: number
+  next_before_id: number
+  has_more: boolean
 }
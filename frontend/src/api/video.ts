@@ -1,5 +1,5 @@
 import { postForm, postJson } from './client'
-import type { Video } from './types'
+import type { ListByAuthorResponse, Video } from './types'
 
 export function publishVideo(input: { title: string; description: string; play_url: string; cover_url: string }) {
   return postJson<Video>('/video/publish', input, { authRequired: true })
@@ -19,10 +19,32 @@ export function uploadCover(file: File) {
   return postForm<UploadResponse>('/video/uploadCover', fd, { authRequired: true })
 }
 
-export function listByAuthorId(authorId: number) {
-  return postJson<Video[]>('/video/listByAuthorID', { author_id: authorId })
+export type AuthorSort = 'newest' | 'most_liked' | 'most_popular'
+
+export function listByAuthorId(
+  authorId: number,
+  opts: { limit?: number; sort?: AuthorSort; cursor?: string; seriesId?: number } = {},
+) {
+  return postJson<ListByAuthorResponse>('/video/listByAuthorID', {
+    author_id: authorId,
+    limit: opts.limit ?? 50,
+    sort: opts.sort,
+    cursor: opts.cursor,
+    series_id: opts.seriesId,
+  })
 }
 
 export function getDetail(id: number) {
   return postJson<Video>('/video/getDetail', { id })
 }
+
+export type ReportPlayResponse = { counted: boolean; completed: boolean }
+
+export function reportPlay(videoId: number, watchDurationMs: number, completionPercent: number, positionMs: number) {
+  return postJson<ReportPlayResponse>('/video/reportPlay', {
+    video_id: videoId,
+    watch_duration_ms: watchDurationMs,
+    completion_percent: completionPercent,
+    position_ms: positionMs,
+  })
+}
@@ -2,15 +2,20 @@ import { defineStore } from 'pinia'
 import { computed, ref } from 'vue'
 
 import { ApiError } from '../api/client'
-import type { Account } from '../api/types'
+import type { RelationAccount } from '../api/types'
 import * as socialApi from '../api/social'
 import { useAuthStore } from './auth'
 
+const PAGE_SIZE = 50
+
 export const useSocialStore = defineStore('social', () => {
   const auth = useAuthStore()
 
-  const followers = ref<Account[]>([])
-  const vloggers = ref<Account[]>([])
+  // 只保存第一页，总数取服务端计数器
+  const followers = ref<RelationAccount[]>([])
+  const vloggers = ref<RelationAccount[]>([])
+  const followersTotal = ref(0)
+  const vloggersTotal = ref(0)
 
   const followersLoading = ref(false)
   const vloggersLoading = ref(false)
@@ -18,12 +23,14 @@ export const useSocialStore = defineStore('social', () => {
   const followersError = ref('')
   const vloggersError = ref('')
 
-  const followerCount = computed(() => followers.value.length)
-  const followingCount = computed(() => vloggers.value.length)
+  const followerCount = computed(() => followersTotal.value)
+  const followingCount = computed(() => vloggersTotal.value)
 
   function clear() {
     followers.value = []
     vloggers.value = []
+    followersTotal.value = 0
+    vloggersTotal.value = 0
     followersError.value = ''
     vloggersError.value = ''
     followersLoading.value = false
@@ -43,8 +50,9 @@ export const useSocialStore = defineStore('social', () => {
     followersLoading.value = true
     followersError.value = ''
     try {
-      const res = await socialApi.getAllFollowers(vloggerId)
+      const res = await socialApi.listFollowers(vloggerId, PAGE_SIZE)
       followers.value = res.followers
+      followersTotal.value = res.total
     } catch (e) {
       followersError.value = e instanceof ApiError ? e.message : String(e)
       followers.value = []
@@ -62,8 +70,9 @@ export const useSocialStore = defineStore('social', () => {
     vloggersLoading.value = true
     vloggersError.value = ''
     try {
-      const res = await socialApi.getAllVloggers(followerId)
-      vloggers.value = res.vloggers
+      const res = await socialApi.listFollowing(followerId, PAGE_SIZE)
+      vloggers.value = res.following
+      vloggersTotal.value = res.total
     } catch (e) {
       vloggersError.value = e instanceof ApiError ? e.message : String(e)
       vloggers.value = []